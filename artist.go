@@ -0,0 +1,80 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/utils"
+	"fmt"
+	"log"
+	"os"
+)
+
+// artist 下载指定作者的全部视频
+// 扫描进度保存在作者目录中,中断后再次运行会从上次的页码继续
+func artist(user *model.User, input string) error {
+	username := utils.ParseUsername(input)
+	if username == "" {
+		return fmt.Errorf("无法解析作者: %s", input)
+	}
+	profile, err := request.GetProfile(user, username)
+	if err != nil {
+		return fmt.Errorf("获取作者主页失败: %w", err)
+	}
+	log.Println("开始下载作者的全部视频:", profile.User.Name, profile.User.Username)
+
+	filePath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.ARTIST_DIR + string(os.PathSeparator) + files.SanitizeFileName(profile.User.Username)
+	if err := files.CheckDirOrCreate(filePath); err != nil {
+		return err
+	}
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := loadCheckpoint(filePath)
+	if err != nil {
+		return err
+	}
+	// 进度页码之前的视频都已经下载完成,作者可能发布了新视频导致列表后移,所以从进度页码的前一页开始扫描
+	startPage := max(checkpoint.Page-1, 0)
+	if startPage > 0 {
+		log.Println("继续上次的下载进度, 从第", startPage, "页开始扫描")
+	}
+
+	// 只下载指定作者的视频,点赞数和禁止条件依然生效
+	// 不修改配置中的下载作者,避免刷新登录信息时保存到配置文件
+	skip := func(user *model.User, video model.Result) bool {
+		if video.User.Username != profile.User.Username {
+			return true
+		}
+		return skipBannedVideo(user, video)
+	}
+
+	var fullCount int
+	pool := newDownloadPool(user)
+	pool.pages = newPageTracker(filePath, checkpoint)
+	scanErr := rangeListPage(startPage, func(page int) (*model.PageDataRoot, error) {
+		return request.GetUserVideoData(user, profile.User.ID, page)
+	}, func(page int, pageData *model.PageDataRoot) (bool, error) {
+		pool.pages.Scan(page)
+		for _, video := range pageData.Results {
+			if pool.Queue(filePath, db, video, skip) {
+				fullCount++
+			}
+		}
+		return false, nil
+	})
+
+	downloadCount := pool.Wait()
+	log.Println("本次一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	if scanErr != nil {
+		return scanErr
+	}
+	// 视频全部扫描并下载完成后才删除进度
+	if err := removeCheckpoint(filePath); err != nil {
+		log.Println("删除下载进度失败:", err)
+	}
+	log.Println("作者视频扫描完成:", profile.User.Username)
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	avatarLock sync.Mutex // 防止多个下载协程同时下载同一个作者的头像
+)
+
+// posterPath 获取视频海报图片的路径
+func posterPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + consts.POSTER_SUFFIX
+}
+
+// saveThumbnail 下载视频缩略图作为海报保存到视频旁边
+func saveThumbnail(user *model.User, videoPath string, video model.Result) {
+	poster := posterPath(videoPath)
+	if files.CheckFileExists(poster) {
+		return
+	}
+	if err := request.DownloadImage(user, request.GetThumbnailUrl(video), poster, 0); err != nil {
+		log.Println("下载视频缩略图失败:", video.Title, err)
+		return
+	}
+	log.Println("视频缩略图下载完成:", poster)
+}
+
+// saveAvatar 下载作者头像到头像目录,每个作者只下载一次
+func saveAvatar(user *model.User, artist model.Artist) {
+	avatarUrl := request.GetAvatarUrl(artist)
+	if avatarUrl == "" {
+		return
+	}
+
+	avatarLock.Lock()
+	defer avatarLock.Unlock()
+
+	avatarDir := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.AVATAR_DIR
+	if err := files.CheckDirOrCreate(avatarDir); err != nil {
+		log.Println("创建头像目录失败:", err)
+		return
+	}
+	avatarPath := avatarDir + string(os.PathSeparator) + files.SanitizeFileName(artist.Username+filepath.Ext(artist.Avatar.Name))
+	if files.CheckFileExists(avatarPath) {
+		return
+	}
+	if err := request.DownloadImage(user, avatarUrl, avatarPath, 0); err != nil {
+		log.Println("下载作者头像失败:", artist.Username, err)
+		return
+	}
+	log.Println("作者头像下载完成:", avatarPath)
+}
+
+// saveArtwork 依据配置下载视频缩略图和作者头像
+func saveArtwork(user *model.User, videoPath string, video model.Result) {
+	if user.SaveThumbnail {
+		saveThumbnail(user, videoPath, video)
+	}
+	if user.SaveAvatar {
+		saveAvatar(user, video.User)
+	}
+}
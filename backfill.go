@@ -0,0 +1,174 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/date"
+	"IwaraDownload/pkg/files"
+	"fmt"
+	"log"
+	"time"
+)
+
+// parseMonth 解析 2006-01 格式的月份
+func parseMonth(s string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("月份格式错误: %s, 格式为 2006-01", s)
+	}
+	return t, nil
+}
+
+// pageLastCreateTime 获取视频列表分页中最后一个视频的创建时间
+func pageLastCreateTime(user *model.User, page int) (time.Time, bool, error) {
+	pageData, err := request.GetDateVideoData(user, page)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(pageData.Results) == 0 {
+		return time.Time{}, false, nil
+	}
+	createTime, err := parseCreateTime(pageData.Results[len(pageData.Results)-1].CreatedAt)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return createTime, true, nil
+}
+
+// seekPage 查找第一个包含早于指定时间视频的页码
+// 视频列表按照发布时间倒序排列,先倍增页码找到范围,再二分查找,避免从第0页开始逐页扫描
+func seekPage(user *model.User, end time.Time) (int, error) {
+	reached := func(page int) (bool, error) {
+		createTime, ok, err := pageLastCreateTime(user, page)
+		if err != nil {
+			return false, err
+		}
+		// 空页说明已经超过最后一页
+		return !ok || !createTime.After(end), nil
+	}
+
+	low, high := 0, 1
+	for {
+		ok, err := reached(high)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			break
+		}
+		low = high + 1
+		high *= 2
+	}
+	for low < high {
+		mid := (low + high) / 2
+		ok, err := reached(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return high, nil
+}
+
+// Backfill 下载指定月份范围内的视频
+// 从结束月份向开始月份连续扫描视频列表,月份之间不会重新从第0页开始,扫描进度保存在下载目录中,中断后再次运行会继续下载
+func Backfill(user *model.User, fromStr string, toStr string) error {
+	from, err := parseMonth(fromStr)
+	if err != nil {
+		return err
+	}
+	to := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	if toStr != "" {
+		if to, err = parseMonth(toStr); err != nil {
+			return err
+		}
+	}
+	if from.After(to) {
+		return fmt.Errorf("开始月份 %s 晚于结束月份 %s", from.Format("2006-01"), to.Format("2006-01"))
+	}
+	end := date.GetLastDayOfMonth(to)
+	log.Println("开始下载", from.Format("2006-01"), "到", to.Format("2006-01"), "的视频")
+
+	checkpoint, err := loadCheckpoint(consts.FlagConf.WorkDIr)
+	if err != nil {
+		return err
+	}
+	var startPage int
+	if checkpoint.From == from.Format("2006-01") && checkpoint.To == to.Format("2006-01") && checkpoint.Page > 0 {
+		// 进度页码之前的视频都已经下载完成,可能有新发布的视频导致列表后移,所以从进度页码的前一页开始扫描
+		startPage = max(checkpoint.Page-1, 0)
+		log.Println("继续上次的下载进度, 从第", startPage, "页开始扫描")
+	} else {
+		checkpoint = &model.Checkpoint{From: from.Format("2006-01"), To: to.Format("2006-01")}
+		log.Println("正在查找结束月份所在的页码")
+		if startPage, err = seekPage(user, end); err != nil {
+			return err
+		}
+		log.Println("从第", startPage, "页开始扫描")
+	}
+
+	var fullCount int
+	var videoDownload bool
+	var currentDir string
+	dbs := make(map[string]model.Data) // 按照目录缓存的数据库
+	pool := newDownloadPool(user)
+	pool.pages = newPageTracker(consts.FlagConf.WorkDIr, checkpoint)
+	scanErr := rangeListPage(startPage, func(page int) (*model.PageDataRoot, error) {
+		return request.GetDateVideoData(user, page)
+	}, func(page int, pageData *model.PageDataRoot) (bool, error) {
+		pool.pages.Scan(page)
+		for _, video := range pageData.Results {
+			createTime, err := parseCreateTime(video.CreatedAt)
+			if err != nil {
+				log.Printf("解析时间失败: %s\n", err.Error())
+				continue
+			}
+			if createTime.After(end) {
+				continue
+			}
+			if createTime.Before(from) {
+				log.Println("视频创建时间", createTime, "早于开始月份,判断为下载任务完成")
+				return true, nil
+			}
+
+			filePath := monthDir(createTime.Year(), int(createTime.Month()))
+			if filePath != currentDir {
+				log.Println("开始下载", createTime.Year(), "年", int(createTime.Month()), "月视频")
+				currentDir = filePath
+			}
+			db, ok := dbs[filePath]
+			if !ok {
+				if err := files.CheckDirOrCreate(filePath); err != nil {
+					return false, err
+				}
+				if db, err = loadVideoDatabase(filePath); err != nil {
+					return false, err
+				}
+				dbs[filePath] = db
+			}
+			if pool.Queue(filePath, db, video, skipVideo) {
+				fullCount++
+				videoDownload = true
+			}
+		}
+		request.SetDelaySwitch(videoDownload)
+		return false, nil
+	})
+
+	downloadCount := pool.Wait()
+	log.Println("一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	if scanErr != nil {
+		return scanErr
+	}
+	// 视频全部扫描并下载完成后才删除进度
+	if err := removeCheckpoint(consts.FlagConf.WorkDIr); err != nil {
+		log.Println("删除下载进度失败:", err)
+	}
+	log.Println("日期范围下载任务完成")
+	return nil
+}
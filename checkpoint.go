@@ -0,0 +1,114 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkpointPath 获取下载目录中的回溯进度文件路径
+func checkpointPath(filePath string) string {
+	return filePath + string(os.PathSeparator) + consts.CHECKPOINT_FILE
+}
+
+// loadCheckpoint 读取下载目录中的回溯进度,没有进度时返回空进度
+func loadCheckpoint(filePath string) (*model.Checkpoint, error) {
+	checkpoint := &model.Checkpoint{}
+	if !files.CheckFileExists(checkpointPath(filePath)) {
+		return checkpoint, nil
+	}
+	data, err := files.ReadFile(checkpointPath(filePath))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// saveCheckpoint 保存回溯进度
+func saveCheckpoint(filePath string, checkpoint *model.Checkpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return files.WriteFile(checkpointPath(filePath), data)
+}
+
+// removeCheckpoint 回溯任务完成后删除进度文件
+func removeCheckpoint(filePath string) error {
+	err := os.Remove(checkpointPath(filePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// pageTracker 按页记录还没有下载完成的视频,只有一页的视频全部下载完成后才推进回溯进度
+// 进度保存为最小的还有视频没有下载完成的页码,扫描中的页码也视为没有完成
+type pageTracker struct {
+	lock       sync.Mutex
+	filePath   string            // 进度文件所在目录
+	checkpoint *model.Checkpoint // 回溯进度
+	current    int               // 正在扫描的页码
+	pending    map[int]int       // 每页还没有下载完成的视频数量
+}
+
+// newPageTracker 创建回溯进度记录
+func newPageTracker(filePath string, checkpoint *model.Checkpoint) *pageTracker {
+	return &pageTracker{
+		filePath:   filePath,
+		checkpoint: checkpoint,
+		current:    checkpoint.Page,
+		pending:    make(map[int]int),
+	}
+}
+
+// Scan 开始扫描新的一页,之前的页码已经扫描完成
+func (t *pageTracker) Scan(page int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.current = page
+	t.save()
+}
+
+// add 正在扫描的页码中有视频加入下载队列,返回视频所在的页码
+func (t *pageTracker) add() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.pending[t.current]++
+	return t.current
+}
+
+// done 视频下载结束(无论成功或失败,失败的视频会记录到失败队列中重试)
+func (t *pageTracker) done(page int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.pending[page]--
+	if t.pending[page] <= 0 {
+		delete(t.pending, page)
+	}
+	t.save()
+}
+
+// save 进度有变化时保存,调用方需要持有锁
+func (t *pageTracker) save() {
+	page := t.current
+	for p := range t.pending {
+		page = min(page, p)
+	}
+	if page == t.checkpoint.Page {
+		return
+	}
+	t.checkpoint.Page = page
+	if err := saveCheckpoint(t.filePath, t.checkpoint); err != nil {
+		log.Println("保存下载进度失败:", err)
+	}
+}
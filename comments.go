@@ -0,0 +1,94 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// commentsPath 获取视频评论存档的路径
+func commentsPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".comments.json"
+}
+
+// fetchComments 分页获取视频的所有评论, parentID 不为空时获取该评论的回复
+func fetchComments(user *model.User, videoID string, parentID string) ([]*model.Comment, int, error) {
+	var comments []*model.Comment
+	var count int
+	for page := 0; ; page++ {
+		pageData, err := request.GetVideoComments(user, videoID, parentID, page)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, comment := range pageData.Results {
+			count++
+			if parentID == "" && comment.NumReplies > 0 {
+				replies, replyCount, err := fetchComments(user, videoID, comment.ID)
+				if err != nil {
+					return nil, 0, err
+				}
+				comment.Replies = replies
+				count += replyCount
+			}
+		}
+		comments = append(comments, pageData.Results...)
+		if len(pageData.Results) == 0 || pageData.Limit <= 0 || (page+1)*pageData.Limit >= pageData.Count {
+			break
+		}
+	}
+	return comments, count, nil
+}
+
+// saveComments 获取视频评论并保存到视频旁边
+func saveComments(user *model.User, videoPath string, video model.Result) {
+	log.Println("正在获取视频评论:", video.Title)
+	comments, count, err := fetchComments(user, video.ID, "")
+	if err != nil {
+		log.Println("获取视频评论失败:", video.Title, err)
+		return
+	}
+
+	archive := model.CommentArchive{
+		VideoID:   video.ID,
+		FetchedAt: time.Now(),
+		Count:     count,
+		Comments:  comments,
+	}
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		log.Println("序列化视频评论失败:", err)
+		return
+	}
+	if err := files.WriteFile(commentsPath(videoPath), data); err != nil {
+		log.Println("写入视频评论失败:", err)
+		return
+	}
+	log.Println("视频评论保存完成, 一共", count, "条评论:", commentsPath(videoPath))
+}
+
+// refreshComments 重新获取最近下载的视频的评论
+func refreshComments(user *model.User) {
+	if !user.ArchiveComments || user.CommentRefreshDays <= 0 {
+		return
+	}
+	refreshAge := time.Duration(user.CommentRefreshDays) * time.Hour * 24
+	err := rangeDownloadedVideo(func(filePath string, videoPath string, data model.VideoData) {
+		if data.DownloadedAt == nil || time.Since(*data.DownloadedAt) > refreshAge {
+			return
+		}
+		if info, err := os.Stat(commentsPath(videoPath)); err == nil && time.Since(info.ModTime()) < consts.COMMENT_REFRESH_INTERVAL {
+			return
+		}
+		saveComments(user, videoPath, *data.Video)
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+}
@@ -1,6 +1,7 @@
 package consts
 
 import (
+	"flag"
 	"io"
 	"log"
 	"os"
@@ -16,11 +17,39 @@ const (
 	DEFAULT_WORKDIR        = "." + string(os.PathSeparator) + MODEL_NAME // 默认下载目录
 	HOT_DIR                = "hot"                                       // 热门视频下载目录
 	HOT_PAGE_DEFAULT_LIMIT = 1                                           // 热门视频下载页数
+	HOT_DEDUP_DAYS         = 30                                          // 热门视频检查最近多少天的目录防止重复下载
+	HOT_REPORT_LIMIT       = 50                                          // 热门报告最多列出的视频数量
+	AVATAR_DIR             = "avatars"                                   // 作者头像保存目录
+	POSTER_SUFFIX          = "-poster.jpg"                               // 视频海报文件后缀
+	EMBED_LIST_FILE        = "embedded.txt"                              // 嵌入视频地址导出文件名
+	SINGLE_DIR             = "single"                                    // 指定视频下载的默认目录
+	ARTIST_DIR             = "artist"                                    // 作者回溯下载目录
+	PLAYLIST_DIR           = "playlist"                                  // 播放列表下载目录
+	TAG_DIR                = "tag"                                       // 标签模式下载目录
+	SEARCH_DIR             = "search"                                    // 搜索模式下载目录
+	LIKED_DIR              = "liked"                                     // 点赞视频下载目录
+	IMAGE_DIR              = "images"                                    // 图片帖子下载目录
 
 	SCAN_STEP       = time.Minute * 10 // 多久执行一次扫描任务
 	MAX_RETRY_TIMES = 5                // 重试次数
 
-	VIDEO_DATABASE = "video.json" // 视频数据库文件名
+	DEFAULT_DOWNLOAD_WORKERS  = 2  // 默认下载协程数量
+	DEFAULT_PROGRESS_INTERVAL = 10 // 默认下载进度输出间隔(秒)
+
+	DEFAULT_DISK_RESERVE = 1024            // 默认下载时磁盘额外保留的剩余空间(MB)
+	DISK_CHECK_INTERVAL  = time.Minute * 5 // 磁盘空间不足时检查剩余空间的间隔
+
+	COMMENT_REFRESH_INTERVAL = time.Hour * 24 // 同一个视频重新获取评论的最小间隔
+
+	DEFAULT_MAX_DOWNLOAD_RETRY = 5                // 下载失败的视频默认最大重试次数
+	RETRY_BASE_DELAY           = time.Minute * 10 // 下载失败后首次重试的等待时间,之后每次失败翻倍
+	RETRY_MAX_DELAY            = time.Hour * 24   // 下载失败后重试的最大等待时间
+	DOWNLOAD_QUEUE_FACTOR      = 2                // 下载队列长度为下载协程数量的倍数
+
+	VIDEO_DATABASE      = "video.json"      // 视频数据库文件名
+	CHECKPOINT_FILE     = "checkpoint.json" // 回溯下载进度文件名
+	PART_FILE_SUFFIX    = ".part"           // 未下载完成的视频临时文件后缀
+	SEGMENT_FILE_SUFFIX = ".seg"            // 分段下载进度文件后缀,跟在临时文件名后面
 )
 
 var (
@@ -40,11 +69,47 @@ type config struct {
 	Subscribed   bool `flag:"subscribed" short:"s" default:"false" usage:"是否订阅模式下载"` // 订阅模式下载
 	Hot          bool `flag:"hot" short:"h" default:"false" usage:"是否进行热门视频模式"`      // 热门视频下载模式
 	HotPageLimit int  `flag:"hotpage" short:"hp" default:"0" usage:"热门视频下载页数"`       // 热门视频下载页数
+
+	HotReport bool `flag:"hotreport" default:"false" usage:"热门报告,统计热门快照中停留时间最长的视频"` // 热门报告
+
+	Workers  int `flag:"workers" short:"w" default:"0" usage:"同时下载的视频数量,比配置文件优先级要高"`       // 下载协程数量
+	Segments int `flag:"segments" default:"0" usage:"单个视频分段下载的连接数量,大于1时开启分段下载,比配置文件优先级要高"` // 分段下载连接数量
+
+	Resolution string `flag:"resolution" short:"r" default:"" usage:"首选下载分辨率,比配置文件优先级要高"` // 首选下载分辨率
+
+	BandwidthLimit int `flag:"bandwidth" short:"b" default:"0" usage:"下载总限速(KB/s),比配置文件优先级要高"` // 下载总限速
+
+	Failed      bool `flag:"failed" default:"false" usage:"列出下载失败队列中的视频"`  // 列出下载失败的视频
+	ClearFailed bool `flag:"clearfailed" default:"false" usage:"清空下载失败队列"` // 清空下载失败的视频
+
+	Nfo   bool `flag:"nfo" default:"false" usage:"为数据库中记录的所有已下载视频生成缺失的nfo文件"` // 补全nfo文件
+	Retag bool `flag:"retag" default:"false" usage:"为数据库中记录的所有已下载视频写入元数据"`    // 补全视频元数据
+
+	Verify  bool `flag:"verify" default:"false" usage:"校验模式,检查数据库中记录的所有已下载视频文件是否完整"`   // 校验已下载视频
+	Requeue bool `flag:"requeue" default:"false" usage:"校验模式下删除损坏的视频文件,并加入下载失败队列重新下载"` // 重新下载损坏的视频
+
+	List   string   `flag:"list" default:"" usage:"指定视频下载模式,从文件中读取视频地址或ID,每行一个,使用 - 表示从标准输入读取"`  // 视频列表文件
+	Output string   `flag:"output" short:"o" default:"" usage:"指定视频下载模式的保存目录,默认为下载目录下的single目录"` // 指定视频下载目录
+	Videos []string `flag:"-"`                                                                   // 命令行中直接指定的视频地址或ID
+
+	Artist   string `flag:"artist" default:"" usage:"作者回溯模式,下载指定作者(用户名或主页地址)的全部视频,中断后再次运行会继续下载"`   // 作者回溯下载
+	Playlist string `flag:"playlist" default:"" usage:"播放列表模式,同步下载指定播放列表(ID或地址)中的视频,多个播放列表使用逗号分隔"` // 播放列表下载
+
+	Tags      string `flag:"tags" default:"" usage:"标签模式,下载服务端按标签筛选出的视频,多个标签使用逗号分隔"` // 标签模式下载
+	Search    string `flag:"search" default:"" usage:"搜索模式,下载服务端按关键词搜索出的视频"`         // 搜索模式下载
+	PageLimit int    `flag:"pagelimit" default:"0" usage:"标签和搜索模式最多扫描的页数,0表示扫描全部"`   // 标签和搜索模式扫描页数
+
+	Liked  bool `flag:"liked" default:"false" usage:"点赞模式,持续同步下载当前账号点赞的视频"`            // 点赞视频同步模式
+	Images bool `flag:"images" default:"false" usage:"图片模式,持续下载新发布的图片帖子,使用和视频相同的下载条件"` // 图片帖子下载模式
+
+	From string `flag:"from" default:"" usage:"日期范围模式的开始月份,格式为 2006-01,从结束月份向开始月份连续下载,中断后再次运行会继续下载"` // 日期范围开始月份
+	To   string `flag:"to" default:"" usage:"日期范围模式的结束月份,格式为 2006-01,默认为当前月份"`                       // 日期范围结束月份
 }
 
 func init() {
 	// 初始化配置
 	structflag.Load(&FlagConf)
+	FlagConf.Videos = flag.Args()
 
 	// 写入日志文件
 	if FlagConf.SaveLog {
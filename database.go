@@ -0,0 +1,181 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	databaseLock sync.Mutex // 本地数据库读写锁,多个下载协程会同时写入数据库
+)
+
+// loadVideoDatabase 读取本地数据库,数据库不存在时返回空数据库
+func loadVideoDatabase(filePath string) (model.Data, error) {
+	dataFileName := filePath + string(os.PathSeparator) + consts.VIDEO_DATABASE
+
+	var db model.Data
+	// 检查文件是否存在
+	if files.CheckFileExists(dataFileName) {
+		// 读取文件
+		data, err := files.ReadFile(dataFileName)
+		if err != nil {
+			return db, err
+		}
+		// 解析文件
+		err = json.Unmarshal(data, &db)
+		if err != nil {
+			return db, err
+		}
+	}
+	if db.VideoMap == nil {
+		db.VideoMap = make(map[string]model.VideoData)
+	}
+	if db.FailedMap == nil {
+		db.FailedMap = make(map[string]model.FailedData)
+	}
+	if db.ImageMap == nil {
+		db.ImageMap = make(map[string]model.ImageData)
+	}
+	return db, nil
+}
+
+// rangeVideoDatabase 遍历下载目录中所有包含本地数据库的目录
+func rangeVideoDatabase(rangeFunc func(filePath string)) error {
+	return filepath.WalkDir(consts.FlagConf.WorkDIr, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != consts.VIDEO_DATABASE {
+			return nil
+		}
+		rangeFunc(filepath.Dir(path))
+		return nil
+	})
+}
+
+// rangeDownloadedVideo 遍历下载目录中所有数据库记录的已下载视频
+func rangeDownloadedVideo(rangeFunc func(filePath string, videoPath string, data model.VideoData)) error {
+	return rangeVideoDatabase(func(filePath string) {
+		db, err := loadVideoDatabase(filePath)
+		if err != nil {
+			log.Println("读取数据库文件失败:", filePath, err)
+			return
+		}
+		for _, data := range db.VideoMap {
+			if data.Video == nil {
+				continue
+			}
+			fileName, _ := findVideoFile(filePath, *data.Video)
+			if fileName == "" {
+				continue
+			}
+			rangeFunc(filePath, filePath+string(os.PathSeparator)+fileName, data)
+		}
+	})
+}
+
+// updateVideoDatabase 读取本地数据库,修改后保存
+func updateVideoDatabase(filePath string, update func(db *model.Data)) error {
+	databaseLock.Lock()
+	defer databaseLock.Unlock()
+	dataFileName := filePath + string(os.PathSeparator) + consts.VIDEO_DATABASE
+
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		return fmt.Errorf("读取数据库文件失败: %w", err)
+	}
+
+	update(&db)
+
+	// 保存数据库
+	data, err := json.Marshal(db)
+	if err != nil {
+		return fmt.Errorf("序列化数据库文件失败: %w", err)
+	}
+	err = files.WriteFile(dataFileName, data)
+	if err != nil {
+		return fmt.Errorf("写入数据库文件失败: %w", err)
+	}
+	return nil
+}
+
+// saveVideoDatabase 保存视频数据到本地数据库
+func saveVideoDatabase(filePath string, videoData model.Result, fileData []*model.Video) {
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		// 保留已有记录中的其他数据
+		addData := db.VideoMap[videoData.ID]
+		addData.Video = &videoData
+		if fileData != nil {
+			// 如果没有文件数据,则不更新文件数据
+			addData.Files = fileData
+		}
+		db.VideoMap[videoData.ID] = addData
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// saveVideoDownloaded 记录视频下载完成的时间
+func saveVideoDownloaded(filePath string, id string) {
+	now := time.Now()
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		data, ok := db.VideoMap[id]
+		if !ok {
+			return
+		}
+		data.DownloadedAt = &now
+		db.VideoMap[id] = data
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// retryDelay 计算第 attempts 次失败后的重试等待时间,每次失败等待时间翻倍
+func retryDelay(attempts int) time.Duration {
+	delay := consts.RETRY_BASE_DELAY
+	for i := 1; i < attempts && delay < consts.RETRY_MAX_DELAY; i++ {
+		delay *= 2
+	}
+	if delay > consts.RETRY_MAX_DELAY {
+		delay = consts.RETRY_MAX_DELAY
+	}
+	return delay
+}
+
+// saveDownloadFailed 记录下载失败的视频到失败队列
+func saveDownloadFailed(filePath string, video model.Result, reason error) {
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		failed := db.FailedMap[video.ID]
+		failed.Video = &video
+		failed.Reason = reason.Error()
+		failed.Attempts++
+		failed.LastAttempt = time.Now()
+		failed.NextAttempt = failed.LastAttempt.Add(retryDelay(failed.Attempts))
+		db.FailedMap[video.ID] = failed
+		log.Printf("视频加入失败队列: %s 失败次数: %d 下次重试时间: %s\n", video.Title, failed.Attempts, failed.NextAttempt.Format(time.DateTime))
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// removeDownloadFailed 从失败队列中移除视频
+func removeDownloadFailed(filePath string, id string) {
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		delete(db.FailedMap, id)
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
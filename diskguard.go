@@ -0,0 +1,91 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/disk"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/progress"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	diskLock     sync.Mutex
+	diskReserved = make(map[string]int64) // 正在下载的视频文件名和开始下载时还需要写入的字节数
+	diskPaused   bool                     // 是否因为磁盘空间不足暂停了下载
+)
+
+// remainingSize 获取视频还需要写入磁盘的字节数
+// 单连接下载的临时文件已经占用了磁盘空间,分段下载的临时文件是预分配的稀疏文件,不能用文件大小计算
+func remainingSize(videoPath string, size int64) int64 {
+	partPath := videoPath + consts.PART_FILE_SUFFIX
+	if files.CheckFileExists(partPath + consts.SEGMENT_FILE_SUFFIX) {
+		return size
+	}
+	if info, err := os.Stat(partPath); err == nil {
+		return max(size-info.Size(), 0)
+	}
+	return size
+}
+
+// reservedSize 获取其他正在下载的视频还需要写入的字节数,已经写入的部分已经从剩余空间中扣除
+// 调用方需要持有 diskLock
+func reservedSize() int64 {
+	statusMap := make(map[string]progress.Status)
+	for _, status := range progress.List() {
+		statusMap[status.Name] = status
+	}
+	var reserved int64
+	for name, size := range diskReserved {
+		if status, ok := statusMap[name]; ok && status.Total > 0 {
+			size = max(status.Total-status.Done, 0)
+		}
+		reserved += size
+	}
+	return reserved
+}
+
+// acquireDiskSpace 等待下载目录所在磁盘有足够的剩余空间,并为即将下载的视频预留空间
+// size 为选择的视频源的文件大小,未知时为0,只检查需要保留的剩余空间
+// 空间不足时所有下载协程都会在这里等待,直到空间释放后自动恢复下载
+// 返回的函数用于在下载结束后释放预留的空间
+func acquireDiskSpace(user *model.User, videoPath string, size int64) func() {
+	reserve := int64(user.DiskReserve) * 1024 * 1024
+	name := filepath.Base(videoPath)
+	remaining := remainingSize(videoPath, size)
+	for {
+		free, err := disk.Free(filepath.Dir(videoPath))
+		if err != nil {
+			// 无法获取剩余空间时不阻止下载
+			log.Println("获取磁盘剩余空间失败:", err)
+			return func() {}
+		}
+
+		diskLock.Lock()
+		need := remaining + reserve + reservedSize()
+		if int64(free) >= need {
+			if diskPaused {
+				diskPaused = false
+				log.Println("磁盘空间已释放,恢复下载")
+			}
+			diskReserved[name] = remaining
+			diskLock.Unlock()
+			return func() {
+				diskLock.Lock()
+				delete(diskReserved, name)
+				diskLock.Unlock()
+			}
+		}
+		if !diskPaused {
+			diskPaused = true
+			log.Printf("磁盘剩余空间不足, 剩余: %s 需要: %s (预留 %s), 暂停下载, 每 %v 检查一次剩余空间\n",
+				progress.FormatSize(int64(free)), progress.FormatSize(need), progress.FormatSize(reserve), consts.DISK_CHECK_INTERVAL)
+		}
+		diskLock.Unlock()
+		time.Sleep(consts.DISK_CHECK_INTERVAL)
+	}
+}
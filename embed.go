@@ -0,0 +1,66 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	embedListLock sync.Mutex // 嵌入视频导出文件的写入锁
+)
+
+// saveEmbedVideo 记录嵌入的外部视频,嵌入视频无法直接下载,不计入下载失败
+func saveEmbedVideo(user *model.User, filePath string, video model.Result) {
+	log.Printf("视频是嵌入的外部视频, 地址: %s 跳过下载\n", video.EmbedUrl)
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		data := db.VideoMap[video.ID]
+		data.Video = &video
+		data.State = model.VideoStateEmbedded
+		db.VideoMap[video.ID] = data
+		delete(db.FailedMap, video.ID)
+	})
+	if err != nil {
+		log.Println(err)
+	}
+
+	if user.ExportEmbed {
+		if err := exportEmbedUrl(video.EmbedUrl); err != nil {
+			log.Println("导出嵌入视频地址失败:", err)
+		}
+	}
+}
+
+// exportEmbedUrl 将嵌入视频地址追加到导出文件中,已经存在的地址不会重复导出
+func exportEmbedUrl(embedUrl string) error {
+	if strings.TrimSpace(embedUrl) == "" {
+		return model.ErrNoVideoUrl
+	}
+	embedListLock.Lock()
+	defer embedListLock.Unlock()
+
+	listPath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.EMBED_LIST_FILE
+	if files.CheckFileExists(listPath) {
+		data, err := files.ReadFile(listPath)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.TrimSpace(line) == embedUrl {
+				return nil
+			}
+		}
+	}
+
+	file, err := os.OpenFile(listPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(embedUrl + "\n")
+	return err
+}
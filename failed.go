@@ -0,0 +1,98 @@
+package main
+
+import (
+	"IwaraDownload/model"
+	"log"
+	"sort"
+	"time"
+)
+
+// retryFailed 重试失败队列中已经到达重试时间的视频
+func retryFailed(user *model.User) {
+	pool := newDownloadPool(user)
+	var retryCount int
+	err := rangeVideoDatabase(func(filePath string) {
+		db, err := loadVideoDatabase(filePath)
+		if err != nil {
+			log.Println("读取数据库文件失败:", filePath, err)
+			return
+		}
+		for _, failed := range db.FailedMap {
+			if failed.Video == nil || failed.Attempts >= user.MaxDownloadRetry || time.Now().Before(failed.NextAttempt) {
+				continue
+			}
+			if failed.Video.EmbedUrl != "" {
+				// 旧版本会将嵌入视频记录为下载失败
+				saveEmbedVideo(user, filePath, *failed.Video)
+				continue
+			}
+			if f, _ := findVideoFile(filePath, *failed.Video); f != "" {
+				// 视频已经通过其他扫描任务下载完成
+				removeDownloadFailed(filePath, failed.Video.ID)
+				continue
+			}
+			log.Printf("重试下载失败的视频: %s 已失败次数: %d\n", failed.Video.Title, failed.Attempts)
+			retryCount++
+			pool.Add(filePath, *failed.Video)
+		}
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+	downloadCount := pool.Wait()
+	if retryCount > 0 {
+		log.Println("本轮一共重试", retryCount, "个下载失败的视频,下载成功", downloadCount)
+	}
+}
+
+// listFailed 列出失败队列中的所有视频
+func listFailed(user *model.User) {
+	var count int
+	err := rangeVideoDatabase(func(filePath string) {
+		db, err := loadVideoDatabase(filePath)
+		if err != nil {
+			log.Println("读取数据库文件失败:", filePath, err)
+			return
+		}
+		ids := make([]string, 0, len(db.FailedMap))
+		for id := range db.FailedMap {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			failed := db.FailedMap[id]
+			count++
+			var title string
+			if failed.Video != nil {
+				title = failed.Video.Title
+			}
+			state := "下次重试时间: " + failed.NextAttempt.Format(time.DateTime)
+			if failed.Attempts >= user.MaxDownloadRetry {
+				state = "已达到最大重试次数,不再重试"
+			}
+			log.Printf("[%s] ID: %s 标题: %s 失败次数: %d 原因: %s %s\n", filePath, id, title, failed.Attempts, failed.Reason, state)
+		}
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+	log.Println("下载失败队列中一共有", count, "个视频")
+}
+
+// clearFailed 清空所有目录的失败队列
+func clearFailed() {
+	var count int
+	err := rangeVideoDatabase(func(filePath string) {
+		err := updateVideoDatabase(filePath, func(db *model.Data) {
+			count += len(db.FailedMap)
+			db.FailedMap = nil
+		})
+		if err != nil {
+			log.Println(err)
+		}
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+	log.Println("已清空下载失败队列,一共清除", count, "个视频")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/config"
+	"log"
+	"slices"
+)
+
+// fetchFollowing 获取当前登录账号关注的全部作者
+func fetchFollowing(user *model.User) ([]string, error) {
+	var artists []string
+	for page := 0; ; page++ {
+		pageData, err := request.GetFollowing(user, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, follow := range pageData.Results {
+			artists = append(artists, follow.User.Username)
+		}
+		if len(pageData.Results) == 0 || pageData.Limit <= 0 || (page+1)*pageData.Limit >= pageData.Count {
+			return artists, nil
+		}
+	}
+}
+
+// syncFollowing 同步账号的关注列表到下载作者
+func syncFollowing(user *model.User) {
+	if !user.SyncFollowing {
+		return
+	}
+	if user.SaveFollowing && user.FollowingArtists == nil {
+		// 启动后第一次同步时使用配置文件中保存的关注列表,同步失败时继续使用
+		user.FollowingArtists = slices.Clone(user.SavedFollowing)
+	}
+	log.Println("正在同步关注列表")
+	artists, err := fetchFollowing(user)
+	if err != nil {
+		// 获取失败时继续使用上次同步的关注列表
+		log.Println("同步关注列表失败:", err)
+		return
+	}
+
+	added, removed := model.DiffArtists(user.FollowingArtists, artists)
+	user.FollowingArtists = artists
+	log.Println("关注列表同步完成, 一共关注", len(artists), "个作者")
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	if len(added) > 0 {
+		log.Printf("新增关注作者: %#v", added)
+	}
+	if len(removed) > 0 {
+		log.Printf("取消关注作者: %#v", removed)
+	}
+	if user.SaveFollowing {
+		user.SavedFollowing = artists
+		if err := config.SaveConfig(user); err != nil {
+			log.Println("配置文件保存失败", err)
+		}
+	}
+}
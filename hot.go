@@ -0,0 +1,83 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"log"
+	"os"
+	"time"
+)
+
+// hotBucketDir 获取热门视频按照扫描日期分类的下载目录
+func hotBucketDir(t time.Time) string {
+	return consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.HOT_DIR + string(os.PathSeparator) + t.Format(time.DateOnly)
+}
+
+// findHotVideoFile 在最近几天的热门目录中查找已经下载的视频,兼容旧版本直接保存在热门目录中的视频
+func findHotVideoFile(scanTime time.Time, video model.Result) (string, string) {
+	dirs := []string{consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.HOT_DIR}
+	for i := 0; i <= consts.HOT_DEDUP_DAYS; i++ {
+		dirs = append(dirs, hotBucketDir(scanTime.AddDate(0, 0, -i)))
+	}
+	for _, dir := range dirs {
+		if fileName, _ := findVideoFile(dir, video); fileName != "" {
+			return dir, fileName
+		}
+	}
+	return "", ""
+}
+
+// saveHotSnapshot 保存热门列表快照到当天目录的数据库中
+func saveHotSnapshot(filePath string, snapshot model.HotSnapshot) {
+	if len(snapshot.Entries) == 0 {
+		return
+	}
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		db.HotSnapshots = append(db.HotSnapshots, snapshot)
+	})
+	if err != nil {
+		log.Println("保存热门快照失败:", err)
+	}
+}
+
+// hotReport 统计所有热门快照,列出在热门列表中停留时间最长的视频
+func hotReport() {
+	hotDir := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.HOT_DIR
+	entries, err := os.ReadDir(hotDir)
+	if err != nil {
+		log.Println("读取热门目录失败:", err)
+		return
+	}
+
+	var snapshots []model.HotSnapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, entry.Name()); err != nil {
+			continue
+		}
+		filePath := hotDir + string(os.PathSeparator) + entry.Name()
+		db, err := loadVideoDatabase(filePath)
+		if err != nil {
+			log.Println("读取数据库文件失败:", filePath, err)
+			continue
+		}
+		snapshots = append(snapshots, db.HotSnapshots...)
+	}
+	if len(snapshots) == 0 {
+		log.Println("没有热门快照记录")
+		return
+	}
+
+	stats := model.HotStats(snapshots)
+	log.Println("一共有", len(snapshots), "次热门扫描记录,", len(stats), "个视频出现在热门列表中")
+	for i, stat := range stats {
+		if i >= consts.HOT_REPORT_LIMIT {
+			break
+		}
+		log.Printf("%d. [%s] %s (ID: %s) 停留天数: %d 出现次数: %d 最高排名: %d 点赞: %d 播放: %d 时间: %s ~ %s\n",
+			i+1, stat.Username, stat.Title, stat.ID, stat.Days, stat.Scans, stat.BestRank, stat.Likes, stat.Views,
+			stat.FirstSeen.Format(time.DateTime), stat.LastSeen.Format(time.DateTime))
+	}
+}
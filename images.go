@@ -0,0 +1,172 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/config"
+	"IwaraDownload/pkg/files"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// imageMonthDir 获取图片帖子按照发布年月分类的下载目录
+func imageMonthDir(createTime time.Time) string {
+	return consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.IMAGE_DIR + string(os.PathSeparator) + strconv.Itoa(createTime.Year()) + string(os.PathSeparator) + strconv.Itoa(int(createTime.Month()))
+}
+
+// imagePostDir 获取图片帖子的目录,帖子中的全部图片保存在同一个目录中
+func imagePostDir(filePath string, image model.Image) string {
+	return filePath + string(os.PathSeparator) + files.SanitizeFileName(fmt.Sprintf("[%s] %s [%s]", image.User.Username, image.Title, image.ID))
+}
+
+// downloadImagePost 下载图片帖子中的全部图片,已经下载的图片不会重复下载
+func downloadImagePost(user *model.User, filePath string, image model.Image) error {
+	detail, err := request.GetImage(user, image.ID)
+	if err != nil {
+		return fmt.Errorf("获取图片帖子详情失败: %w", err)
+	}
+	postDir := imagePostDir(filePath, *detail)
+	if err := files.CheckDirOrCreate(postDir); err != nil {
+		return err
+	}
+
+	var names []string
+	for i, file := range detail.Files {
+		// 使用序号作为前缀,保持图片在帖子中的顺序
+		name := files.SanitizeFileName(fmt.Sprintf("%02d-%s", i+1, file.Name))
+		imagePath := postDir + string(os.PathSeparator) + name
+		if files.CheckFileExists(imagePath) {
+			names = append(names, name)
+			continue
+		}
+		if err := request.DownloadImage(user, request.GetOriginalImageUrl(file), imagePath, file.Size); err != nil {
+			return fmt.Errorf("下载图片失败: %s %w", name, err)
+		}
+		names = append(names, name)
+		// 每下载完成一张图片就记录一次,下载中断时数据库中也能看到已经下载的图片
+		if err := saveImageDatabase(filePath, detail, names, nil); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	return saveImageDatabase(filePath, detail, names, &now)
+}
+
+// saveImageDatabase 保存图片帖子的下载进度,downloadedAt 为空表示帖子中还有图片没有下载完成
+func saveImageDatabase(filePath string, image *model.Image, names []string, downloadedAt *time.Time) error {
+	return updateVideoDatabase(filePath, func(db *model.Data) {
+		db.ImageMap[image.ID] = model.ImageData{Image: image, Files: slices.Clone(names), DownloadedAt: downloadedAt}
+	})
+}
+
+// Images 下载上次扫描之后发布的图片帖子,第一次扫描时下载当月发布的图片帖子
+// 返回下载失败的帖子数量
+func Images(user *model.User, lastScanTime time.Time) (int, error) {
+	if lastScanTime.IsZero() {
+		now := time.Now()
+		lastScanTime = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	}
+
+	var fullCount, downloadCount, failedCount int
+	dbs := make(map[string]model.Data) // 按照目录缓存的数据库
+	defer func() {
+		log.Println("本轮扫描一共需要下载", fullCount, "个图片帖子,本次下载", downloadCount, "失败", failedCount)
+	}()
+
+	for page := 0; ; page++ {
+		log.Printf("正在获取第%d页图片列表\n", page)
+		pageData, err := request.GetImageData(user, page)
+		if err != nil {
+			log.Printf("获取图片列表失败: %s\n", err.Error())
+			return failedCount, err
+		}
+		log.Println("图片列表获取成功")
+
+		for _, image := range pageData.Results {
+			log.Println("处理图片帖子:", image.Title)
+			createTime, err := parseCreateTime(image.CreatedAt)
+			if err != nil {
+				log.Printf("解析时间失败: %s\n", err.Error())
+				continue
+			}
+			if createTime.Before(lastScanTime) {
+				log.Println("图片帖子创建时间", createTime, "早于", lastScanTime, ",判断为下载任务完成")
+				return failedCount, nil
+			}
+			if skipPost(user, image.User, image.Tags, image.NumLikes) {
+				log.Println("图片帖子不符合下载条件,跳过...")
+				continue
+			}
+			fullCount++
+
+			filePath := imageMonthDir(createTime)
+			db, ok := dbs[filePath]
+			if !ok {
+				if err := files.CheckDirOrCreate(filePath); err != nil {
+					return failedCount, err
+				}
+				if db, err = loadVideoDatabase(filePath); err != nil {
+					return failedCount, err
+				}
+				dbs[filePath] = db
+			}
+			if db.ImageMap[image.ID].DownloadedAt != nil {
+				log.Println("图片帖子已下载,跳过...")
+				continue
+			}
+
+			if err := downloadImagePost(user, filePath, image); err != nil {
+				log.Println("图片帖子下载失败:", image.Title, err)
+				failedCount++
+				continue
+			}
+			downloadCount++
+			log.Println("图片帖子下载完成:", image.Title)
+		}
+
+		if len(pageData.Results) == 0 || pageData.Limit <= 0 || (page+1)*pageData.Limit >= pageData.Count {
+			return failedCount, nil
+		}
+	}
+}
+
+func images() {
+	log.Println("开始下载图片帖子")
+	config.Config.PrintLimit()
+
+	lastScanTime := time.Time{}
+	retryTimes := 0
+	for {
+		start := time.Now()
+		log.Println("开始扫描图片帖子")
+		failedCount, err := Images(config.Config, lastScanTime)
+		if err != nil {
+			if retryTimes > consts.MAX_RETRY_TIMES {
+				log.Println("重试次数过多,程序退出")
+				os.Exit(1)
+			}
+			log.Println("扫描图片帖子任务失败", err, "开始重试")
+			retryTimes++
+			continue
+		}
+		log.Println("扫描图片帖子任务完成")
+		// 有下载失败的帖子时不更新扫描时间,下一轮扫描时重新下载
+		if failedCount == 0 {
+			lastScanTime = start
+		}
+		useTime := time.Since(start)
+		log.Println("本次扫描任务耗时:", useTime)
+		log.Println("===============================================================")
+		retryTimes = 0
+
+		if useTime < consts.SCAN_STEP {
+			time.Sleep(consts.SCAN_STEP - useTime)
+		}
+	}
+}
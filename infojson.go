@@ -0,0 +1,63 @@
+package main
+
+import (
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// infoJsonPath 获取视频信息文件的路径
+func infoJsonPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".info.json"
+}
+
+// fileSHA256 计算文件的 sha256
+func fileSHA256(filePath string) (string, int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// saveInfoJson 在视频旁边保存包含视频数据和下载信息的信息文件
+func saveInfoJson(videoPath string, video model.Result, src *model.Video) {
+	hash, size, err := fileSHA256(videoPath)
+	if err != nil {
+		log.Println("计算视频文件哈希失败:", videoPath, err)
+		return
+	}
+	info := model.NewInfoJson(video, src, filepath.Base(videoPath), model.DownloadInfo{
+		Time:       time.Now(),
+		Resolution: src.Name,
+		Size:       size,
+		SHA256:     hash,
+	})
+	info.Thumbnail = request.GetThumbnailUrl(video)
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		log.Println("序列化视频信息文件失败:", err)
+		return
+	}
+	if err := files.WriteFile(infoJsonPath(videoPath), data); err != nil {
+		log.Println("写入视频信息文件失败:", err)
+		return
+	}
+	log.Println("视频信息文件保存完成:", infoJsonPath(videoPath))
+}
@@ -0,0 +1,23 @@
+package request
+
+import (
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/limiter"
+	"sync"
+	"time"
+)
+
+var (
+	downloadLimiter     *limiter.Bucket // 所有下载连接共享的限速器
+	downloadLimiterOnce sync.Once
+)
+
+// getDownloadLimiter 获取下载限速器,限速值依据用户配置的限速时间段实时计算
+func getDownloadLimiter(user *model.User) *limiter.Bucket {
+	downloadLimiterOnce.Do(func() {
+		downloadLimiter = limiter.NewBucket(func() int64 {
+			return user.CurrentBandwidthLimit(time.Now())
+		})
+	})
+	return downloadLimiter
+}
@@ -2,11 +2,17 @@ package request
 
 import (
 	"IwaraDownload/model"
+	"IwaraDownload/pkg/progress"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -39,7 +45,11 @@ var (
 		"Priority":           "u=1, i",
 	}
 
-	DelaySwitch bool = true // 是否开启请求延时
+	noDelay atomic.Bool // 是否关闭请求延时
+
+	// apiLock 接口请求锁,所有接口请求(登录,视频列表,视频地址等)串行执行,保证请求延时对所有下载协程生效
+	// 视频文件的下载不使用该锁,可以并发进行
+	apiLock sync.Mutex
 )
 
 const (
@@ -48,30 +58,118 @@ const (
 	reqDelay   = time.Second * 30                                                                                                       // 请求延时
 )
 
+// StatusCodeError 请求返回了非预期的状态码
+type StatusCodeError int
+
+// Error 实现error接口
+func (e StatusCodeError) Error() string {
+	return fmt.Sprintf("status code is %v", int(e))
+}
+
+// rangeNotSatisfiableError 请求的范围超出了服务器上的文件大小
+type rangeNotSatisfiableError struct {
+	total int64 // 服务器返回的文件总大小,未知时为-1
+}
+
+// Error 实现error接口
+func (e *rangeNotSatisfiableError) Error() string {
+	return StatusCodeError(http.StatusRequestedRangeNotSatisfiable).Error()
+}
+
+// Unwrap 兼容按照状态码判断错误
+func (e *rangeNotSatisfiableError) Unwrap() error {
+	return StatusCodeError(http.StatusRequestedRangeNotSatisfiable)
+}
+
 // 保存页面文本到文件
-func saveWebRspToFile(filePath string, url string, method METHOD, user *model.User, body string) error {
-	rsp, err := reqWeb(url, method, user, body, nil)
+// 如果目标文件已经存在,则使用 Range 请求从已有的文件末尾继续下载
+// tracker 不为空时统计下载进度
+func saveWebRspToFile(filePath string, url string, method METHOD, user *model.User, body string, tracker *progress.Tracker) error {
+	var offset int64
+	if info, err := os.Stat(filePath); err == nil {
+		offset = info.Size()
+	}
+
+	var addHeader map[string]string
+	if offset > 0 {
+		addHeader = map[string]string{"Range": "bytes=" + strconv.FormatInt(offset, 10) + "-"}
+	}
+	// 文件下载不经过接口请求延时,使用当前的登录信息快照发送请求
+	jwt, cookies := userSnapshot(user)
+	rsp, err := doReq(url, method, jwt, cookies, body, addHeader)
 	if err != nil {
+		var rangeErr *rangeNotSatisfiableError
+		if offset > 0 && errors.As(err, &rangeErr) {
+			if rangeErr.total == offset {
+				// 请求的范围已经超出文件大小,并且已有文件和服务器上的文件一样大,说明文件已经下载完成
+				return nil
+			}
+			// 已有文件和服务器上的文件大小不一致,清空后重新下载
+			log.Printf("临时文件大小 %d 和服务器文件大小 %d 不一致,重新下载: %s\n", offset, rangeErr.total, filePath)
+			if err := os.Truncate(filePath, 0); err != nil {
+				return err
+			}
+			return saveWebRspToFile(filePath, url, method, user, body, tracker)
+		}
 		return err
 	}
 	defer rsp.Body.Close()
 
-	// 创建本地文件
-	file, err := os.Create(filePath)
+	// 服务器支持续传时追加写入,否则从头开始写入
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	total := rsp.ContentLength
+	tracker.SetDone(0)
+	if rsp.StatusCode == http.StatusPartialContent {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		total = parseContentRangeTotal(rsp.Header.Get("Content-Range"))
+		tracker.SetDone(offset)
+	}
+	if total > 0 {
+		tracker.SetTotal(total)
+	}
+	file, err := os.OpenFile(filePath, flag, 0666)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
 	// 将数据写入文件
-	_, err = io.Copy(file, rsp.Body)
+	var w io.Writer = file
+	if tracker != nil {
+		w = io.MultiWriter(file, tracker)
+	}
+	_, err = io.Copy(w, getDownloadLimiter(user).Reader(rsp.Body))
 	if err != nil {
 		return err
 	}
 
+	// 校验写入的字节数是否和服务器返回的大小一致
+	if total > 0 {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() != total {
+			return fmt.Errorf("%w: 期望 %d 字节, 实际 %d 字节", model.ErrVideoSizeMismatch, total, info.Size())
+		}
+	}
+
 	return nil
 }
 
+// parseContentRangeTotal 解析 Content-Range 中的文件总大小, 解析失败返回-1
+func parseContentRangeTotal(contentRange string) int64 {
+	index := strings.LastIndex(contentRange, "/")
+	if index < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(contentRange[index+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
 // 获取页面主页文本
 func getWeb(url string, method METHOD, user *model.User, body string, addHeader map[string]string) ([]byte, error) {
 	rsp, err := reqWeb(url, method, user, body, addHeader)
@@ -83,9 +181,14 @@ func getWeb(url string, method METHOD, user *model.User, body string, addHeader
 	return io.ReadAll(rsp.Body)
 }
 
+// SetDelaySwitch 设置是否开启请求延时
+func SetDelaySwitch(on bool) {
+	noDelay.Store(!on)
+}
+
 // requestDelay 请求延时
 func requestDelay() {
-	if !DelaySwitch {
+	if noDelay.Load() {
 		time.Sleep(time.Second * 3) // 虽然有延时开关,但是至少是3秒
 		return
 	}
@@ -110,9 +213,22 @@ const (
 	renewCookies = true
 )
 
-// 发送请求
+// 发送接口请求,调用方需要持有 apiLock
 func reqWeb(url string, method METHOD, user *model.User, bodyStr string, addHeader map[string]string) (*http.Response, error) {
 	requestDelay()
+	jwt, cookies := user.AuthSnapshot()
+	rsp, err := doReq(url, method, jwt, cookies, bodyStr, addHeader)
+	if err != nil {
+		return nil, err
+	}
+	if renewCookies {
+		user.SetCookies(rsp.Cookies())
+	}
+	return rsp, nil
+}
+
+// 使用指定的jwt和cookie发送请求
+func doReq(url string, method METHOD, jwt string, cookies []*http.Cookie, bodyStr string, addHeader map[string]string) (*http.Response, error) {
 	c := http.Client{}
 	req, err := http.NewRequest(string(method), url, strings.NewReader(bodyStr))
 	if err != nil {
@@ -122,7 +238,6 @@ func reqWeb(url string, method METHOD, user *model.User, bodyStr string, addHead
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
-	jwt := user.GetAuthorization()
 	if jwt != "" {
 		req.Header.Set("Authorization", jwt)
 	}
@@ -130,18 +245,20 @@ func reqWeb(url string, method METHOD, user *model.User, bodyStr string, addHead
 		req.Header.Set(k, v)
 	}
 	// 设置全局Cookie
-	for _, cookie := range user.Cookies {
+	for _, cookie := range cookies {
 		req.AddCookie(cookie)
 	}
 	rsp, err := c.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	if rsp.StatusCode != 200 {
-		return nil, fmt.Errorf("status code is %v", rsp.StatusCode)
+	if rsp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		rsp.Body.Close()
+		return nil, &rangeNotSatisfiableError{total: parseContentRangeTotal(rsp.Header.Get("Content-Range"))}
 	}
-	if renewCookies {
-		user.Cookies = rsp.Cookies()
+	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusPartialContent {
+		rsp.Body.Close()
+		return nil, StatusCodeError(rsp.StatusCode)
 	}
 	return rsp, nil
 }
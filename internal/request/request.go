@@ -1,13 +1,23 @@
 package request
 
 import (
+	"IwaraDownload/consts"
 	"IwaraDownload/model"
 	"IwaraDownload/pkg/config"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/progress"
 	"IwaraDownload/pkg/utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 )
 
 const (
@@ -16,6 +26,22 @@ const (
 	apiTokenUrl     = apiHost + "/user/token"                         // 获取token地址
 	apiPageUrl      = apiHost + "/videos?rating=all&limit=32&page=%d" // 视频列表地址
 	apiVideoMainUrl = apiHost + "/video/%s"                           // 视频主页地址
+	apiCommentUrl   = apiHost + "/video/%s/comments?page=%d"          // 视频评论地址
+	apiProfileUrl   = apiHost + "/profile/%s"                         // 用户主页地址
+	apiUserPageUrl  = apiPageUrl + "&sort=date&user=%s"               // 指定用户的视频列表地址
+	apiPlaylistUrl  = apiHost + "/playlist/%s?page=%d"                // 播放列表地址
+	apiTagPageUrl   = apiPageUrl + "&sort=date&tags=%s"               // 指定标签的视频列表地址
+	apiSearchUrl    = apiHost + "/search?type=video&page=%d&query=%s" // 搜索视频地址
+	apiFollowingUrl = apiHost + "/user/%s/following?page=%d"          // 用户关注列表地址
+	apiFavoriteUrl  = apiHost + "/favorites/videos?page=%d"           // 点赞视频列表地址
+	apiImagePageUrl = apiHost + "/images?rating=all&limit=32&page=%d" // 图片列表地址
+	apiImageMainUrl = apiHost + "/image/%s"                           // 图片帖子地址
+
+	imageHost           = "https://i.iwara.tv"                                 // 图片地址
+	imageThumbnailUrl   = imageHost + "/image/thumbnail/%s/thumbnail-%02d.jpg" // 视频缩略图地址
+	imageCustomThumbUrl = imageHost + "/image/thumbnail/%s/%s.jpg"             // 视频自定义缩略图地址
+	imageAvatarUrl      = imageHost + "/image/avatar/%s/%s"                    // 用户头像地址
+	imageOriginalUrl    = imageHost + "/image/original/%s/%s"                  // 原图地址
 )
 
 // 流程为: 登录 -> 获取token -> 获取视频列表 -> 视频主页 -> 获取视频地址 -> 下载视频
@@ -101,6 +127,9 @@ func RefreshAccessToken(user *model.User) error {
 
 // GetVideoData 获取视频地址
 func GetVideoData(user *model.User, page int) (*model.PageDataRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
 	err := RefreshAccessToken(user)
 	if err != nil {
 		return nil, err
@@ -117,6 +146,67 @@ func GetVideoData(user *model.User, page int) (*model.PageDataRoot, error) {
 		// 默认全部下载模式,依据时间排序
 		url = url + "&sort=date"
 	}
+	return getPageData(user, url)
+}
+
+// GetDateVideoData 获取按照发布时间排序的视频列表,不受热门模式配置的影响,用于日期范围下载
+func GetDateVideoData(user *model.User, page int) (*model.PageDataRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf(apiPageUrl, page)
+	if user.Subscribe {
+		// 获取订阅的视频
+		url = url + "&subscribed=true"
+	} else {
+		url = url + "&sort=date"
+	}
+	return getPageData(user, url)
+}
+
+// GetUserVideoData 获取指定用户的视频列表,按照发布时间排序
+func GetUserVideoData(user *model.User, userID string, page int) (*model.PageDataRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	return getPageData(user, fmt.Sprintf(apiUserPageUrl, page, userID))
+}
+
+// GetTagVideoData 获取包含指定标签的视频列表,按照发布时间排序,多个标签之间为并且的关系
+func GetTagVideoData(user *model.User, tags []string, page int) (*model.PageDataRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	return getPageData(user, fmt.Sprintf(apiTagPageUrl, page, url.QueryEscape(strings.Join(tags, ","))))
+}
+
+// SearchVideoData 依据关键词搜索视频
+func SearchVideoData(user *model.User, query string, page int) (*model.PageDataRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	return getPageData(user, fmt.Sprintf(apiSearchUrl, page, url.QueryEscape(query)))
+}
+
+// getPageData 获取并解析视频列表分页数据
+func getPageData(user *model.User, url string) (*model.PageDataRoot, error) {
 	body, err := getWeb(url, GET, user, "", nil)
 	if err != nil {
 		return nil, err
@@ -133,8 +223,176 @@ func GetVideoData(user *model.User, page int) (*model.PageDataRoot, error) {
 	return &rsp, err
 }
 
+// GetPlaylistData 获取播放列表中的视频
+func GetPlaylistData(user *model.User, playlistID string, page int) (*model.PlaylistPageRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiPlaylistUrl, playlistID, page), GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.PlaylistPageRoot
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// GetFollowing 获取当前登录账号的关注列表
+func GetFollowing(user *model.User, page int) (*model.FollowPageRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := user.UserID()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiFollowingUrl, userID, page), GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.FollowPageRoot
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// GetLikedVideoData 获取当前登录账号点赞的视频列表,最近点赞的视频在前
+func GetLikedVideoData(user *model.User, page int) (*model.PageDataRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiFavoriteUrl, page), GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.FavoritePageRoot
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.PageData(), nil
+}
+
+// GetImageData 获取图片列表,按照发布时间排序
+func GetImageData(user *model.User, page int) (*model.ImagePageRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiImagePageUrl, page)+"&sort=date", GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.ImagePageRoot
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// GetImage 获取图片帖子详情,包含帖子中的全部图片
+func GetImage(user *model.User, imageID string) (*model.Image, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiImageMainUrl, imageID), GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.Image
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// GetProfile 依据用户名获取用户主页信息
+func GetProfile(user *model.User, username string) (*model.Profile, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiProfileUrl, username), GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.Profile
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// GetVideo 依据视频ID获取视频详情
+func GetVideo(user *model.User, videoID string) (*model.Result, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := getWeb(fmt.Sprintf(apiVideoMainUrl, videoID), GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.Result
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
 // GetVideoDownloadUrl 获取视频下载地址
 func GetVideoDownloadUrl(user *model.User, videoData model.Result) ([]*model.Video, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
 	err := RefreshAccessToken(user)
 	if err != nil {
 		return nil, err
@@ -155,6 +413,9 @@ func GetVideoDownloadUrl(user *model.User, videoData model.Result) ([]*model.Vid
 		return nil, err
 	}
 
+	if rsp.EmbedUrl != "" {
+		return nil, &model.EmbedVideoError{EmbedUrl: rsp.EmbedUrl}
+	}
 	fileUrl := rsp.FileUrl
 	if fileUrl == "" {
 		return nil, model.ErrNoVideoUrl
@@ -196,9 +457,137 @@ func GetVideoDownloadUrl(user *model.User, videoData model.Result) ([]*model.Vid
 	return videoSrc, nil
 }
 
+// GetVideoComments 获取视频评论, parentID 不为空时获取该评论的回复
+func GetVideoComments(user *model.User, videoID string, parentID string, page int) (*model.CommentPageRoot, error) {
+	apiLock.Lock()
+	defer apiLock.Unlock()
+
+	err := RefreshAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf(apiCommentUrl, videoID, page)
+	if parentID != "" {
+		url = url + "&parent=" + parentID
+	}
+	body, err := getWeb(url, GET, user, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp model.CommentPageRoot
+	err = json.Unmarshal(body, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+const (
+	maxDownloadRetry   = 5                // 单个视频下载中断后的最大续传次数
+	downloadRetryDelay = time.Second * 10 // 下载中断后等待多久再续传
+)
+
 // Download 下载视频
-func Download(user *model.User, videoUrl string, filePath string) error {
-	downloadUrl := "https:" + videoUrl
-	err := saveWebRspToFile(filePath, downloadUrl, GET, user, "")
+// 视频会先写入 .part 临时文件,连接中断后使用 Range 请求续传,全部下载完成后才重命名为目标文件名
+func Download(user *model.User, video model.Result, videoSrc *model.Video, filePath string) error {
+	partPath := filePath + consts.PART_FILE_SUFFIX
+	videoUrl := videoSrc.Src.Download
+
+	// 统计下载进度,定时输出到日志并通知订阅者
+	tracker := progress.Start(filepath.Base(filePath), video.ExpectSize(videoSrc.Name), time.Duration(user.ProgressInterval)*time.Second)
+	defer tracker.Finish()
+
+	var err error
+	for i := 0; i <= maxDownloadRetry; i++ {
+		if i > 0 {
+			log.Printf("视频下载中断: %s, %v 后进行第%d次续传\n", err.Error(), downloadRetryDelay, i)
+			time.Sleep(downloadRetryDelay)
+		}
+
+		err = saveVideoFile(user, partPath, "https:"+videoUrl, tracker)
+		if err == nil {
+			// 下载完成后校验文件,未通过校验的文件无法续传修复,直接删除
+			if err = files.VerifyVideoFile(partPath, video.ExpectSize(videoSrc.Name)); err != nil {
+				os.Remove(partPath)
+				return err
+			}
+			return os.Rename(partPath, filePath)
+		}
+
+		if downloadUrlExpired(err) {
+			// 下载地址带有签名和过期时间,过期后需要重新获取
+			log.Println("视频下载地址已失效,重新获取下载地址")
+			newUrl, urlErr := refreshDownloadUrl(user, video, videoSrc.Name)
+			if urlErr != nil {
+				return urlErr
+			}
+			videoUrl = newUrl
+		}
+	}
 	return err
 }
+
+// downloadUrlExpired 检查下载错误是否是因为下载地址过期
+func downloadUrlExpired(err error) bool {
+	var statusErr StatusCodeError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch int(statusErr) {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// refreshDownloadUrl 重新获取指定分辨率的视频下载地址
+func refreshDownloadUrl(user *model.User, video model.Result, name string) (string, error) {
+	videoUrl, err := GetVideoDownloadUrl(user, video)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range videoUrl {
+		if v.Name == name {
+			return v.Src.Download, nil
+		}
+	}
+	return "", model.ErrNoVideoUrl
+}
+
+// GetThumbnailUrl 获取视频缩略图地址,优先使用自定义缩略图
+func GetThumbnailUrl(video model.Result) string {
+	if video.CustomThumbnail != nil && video.CustomThumbnail.ID != "" {
+		return fmt.Sprintf(imageCustomThumbUrl, video.CustomThumbnail.ID, video.CustomThumbnail.ID)
+	}
+	return fmt.Sprintf(imageThumbnailUrl, video.File.ID, video.Thumbnail)
+}
+
+// GetAvatarUrl 获取用户头像地址,用户没有头像时返回空字符串
+func GetAvatarUrl(artist model.Artist) string {
+	if artist.Avatar.ID == "" || artist.Avatar.Name == "" {
+		return ""
+	}
+	return fmt.Sprintf(imageAvatarUrl, artist.Avatar.ID, artist.Avatar.Name)
+}
+
+// GetOriginalImageUrl 获取图片原图地址
+func GetOriginalImageUrl(file model.File) string {
+	return fmt.Sprintf(imageOriginalUrl, file.ID, file.Name)
+}
+
+// DownloadImage 下载图片
+// expectSize 大于0时校验下载的文件大小,服务器没有返回文件大小时也能发现不完整的图片
+func DownloadImage(user *model.User, imageUrl string, filePath string, expectSize int64) error {
+	partPath := filePath + consts.PART_FILE_SUFFIX
+	if err := saveWebRspToFile(partPath, imageUrl, GET, user, "", nil); err != nil {
+		return err
+	}
+	if err := files.VerifyFileSize(partPath, expectSize); err != nil {
+		// 大小不一致时删除临时文件,下次重新下载
+		os.Remove(partPath)
+		return err
+	}
+	return os.Rename(partPath, filePath)
+}
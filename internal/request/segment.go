@@ -0,0 +1,252 @@
+package request
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/progress"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	minSegmentSize      = 1024 * 1024 * 8 // 每个分段的最小大小,文件太小时减少分段数量
+	segmentStateSaveGap = time.Second * 5 // 分段下载进度的保存间隔
+)
+
+var (
+	errRangeNotSupported = errors.New("服务器不支持分段下载")
+)
+
+// segment 单个分段的下载进度
+type segment struct {
+	Start int64 `json:"start"` // 起始位置
+	End   int64 `json:"end"`   // 结束位置(包含)
+	Done  int64 `json:"done"`  // 已下载的字节数
+}
+
+// segmentState 分段下载的进度,保存到文件中用于中断后续传
+type segmentState struct {
+	Total    int64      `json:"total"`    // 文件总大小
+	Segments []*segment `json:"segments"` // 分段列表
+
+	lock sync.Mutex
+	path string // 进度文件路径
+}
+
+// save 保存分段下载进度
+func (s *segmentState) save() error {
+	s.lock.Lock()
+	data, err := json.Marshal(s)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return files.WriteFile(s.path, data)
+}
+
+// segmentWriter 写入文件指定位置并记录分段下载进度
+type segmentWriter struct {
+	w       io.Writer
+	state   *segmentState
+	seg     *segment
+	tracker *progress.Tracker
+}
+
+// Write 实现io.Writer接口
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.state.lock.Lock()
+	w.seg.Done += int64(n)
+	w.state.lock.Unlock()
+	w.tracker.Add(int64(n))
+	return n, err
+}
+
+// userSnapshot 获取当前用户登录信息的快照,用于不经过接口锁的文件下载请求
+func userSnapshot(user *model.User) (string, []*http.Cookie) {
+	return user.AuthSnapshot()
+}
+
+// saveVideoFile 下载视频文件,配置了分段下载时优先使用多连接分段下载
+func saveVideoFile(user *model.User, filePath string, url string, tracker *progress.Tracker) error {
+	if user.DownloadSegments > 1 {
+		// 已经存在单连接下载的临时文件时,继续使用单连接续传
+		singlePart := files.CheckFileExists(filePath) && !files.CheckFileExists(filePath+consts.SEGMENT_FILE_SUFFIX)
+		if !singlePart {
+			err := saveWebRspToFileSegmented(filePath, url, user, user.DownloadSegments, tracker)
+			if !errors.Is(err, errRangeNotSupported) {
+				return err
+			}
+			log.Println("服务器不支持分段下载,使用单连接下载")
+			// 分段下载的临时文件已经预分配为完整大小,不能用于单连接续传,需要和进度文件一起删除
+			os.Remove(filePath)
+			os.Remove(filePath + consts.SEGMENT_FILE_SUFFIX)
+		}
+	}
+	return saveWebRspToFile(filePath, url, GET, user, "", tracker)
+}
+
+// probeContentLength 使用 Range 请求探测文件大小,服务器不支持 Range 时返回 errRangeNotSupported
+func probeContentLength(url string, user *model.User) (int64, error) {
+	jwt, cookies := userSnapshot(user)
+	rsp, err := doReq(url, GET, jwt, cookies, "", map[string]string{"Range": "bytes=0-0"})
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusPartialContent {
+		return 0, errRangeNotSupported
+	}
+	total := parseContentRangeTotal(rsp.Header.Get("Content-Range"))
+	if total <= 0 {
+		return 0, errRangeNotSupported
+	}
+	return total, nil
+}
+
+// newSegmentState 读取已有的分段下载进度,不存在时探测文件大小并重新划分分段
+func newSegmentState(filePath string, url string, user *model.User, count int) (*segmentState, error) {
+	statePath := filePath + consts.SEGMENT_FILE_SUFFIX
+	state := &segmentState{path: statePath}
+	if files.CheckFileExists(statePath) && files.CheckFileExists(filePath) {
+		data, err := files.ReadFile(statePath)
+		if err == nil && json.Unmarshal(data, state) == nil && state.Total > 0 {
+			log.Println("读取到分段下载进度,继续下载")
+			return state, nil
+		}
+		log.Println("分段下载进度文件损坏,重新下载")
+		state = &segmentState{path: statePath}
+	}
+
+	total, err := probeContentLength(url, user)
+	if err != nil {
+		return nil, err
+	}
+	if maxCount := int(total / minSegmentSize); count > maxCount {
+		count = maxCount
+	}
+	if count <= 1 {
+		// 文件太小,没有必要分段下载
+		return nil, errRangeNotSupported
+	}
+
+	state.Total = total
+	segmentSize := total / int64(count)
+	for i := 0; i < count; i++ {
+		seg := &segment{Start: int64(i) * segmentSize, End: int64(i+1)*segmentSize - 1}
+		if i == count-1 {
+			seg.End = total - 1
+		}
+		state.Segments = append(state.Segments, seg)
+	}
+
+	// 预分配文件空间
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err := file.Truncate(total); err != nil {
+		return nil, err
+	}
+	return state, state.save()
+}
+
+// saveWebRspToFileSegmented 将文件划分为多个分段,使用多个连接并发下载到预分配的文件中
+func saveWebRspToFileSegmented(filePath string, url string, user *model.User, count int, tracker *progress.Tracker) error {
+	state, err := newSegmentState(filePath, url, user, count)
+	if err != nil {
+		return err
+	}
+
+	var done int64
+	for _, seg := range state.Segments {
+		done += seg.Done
+	}
+	tracker.SetTotal(state.Total)
+	tracker.SetDone(done)
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 定时保存下载进度,程序意外退出后也能从最近的进度续传
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(segmentStateSaveGap)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				state.save()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	log.Printf("开始分段下载, 文件大小: %d 字节, 分段数量: %d\n", state.Total, len(state.Segments))
+	var wg sync.WaitGroup
+	errs := make([]error, len(state.Segments))
+	for i, seg := range state.Segments {
+		wg.Add(1)
+		go func(i int, seg *segment) {
+			defer wg.Done()
+			errs[i] = downloadSegment(file, url, user, state, seg, tracker)
+		}(i, seg)
+	}
+	wg.Wait()
+	close(stop)
+
+	if err := state.save(); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return os.Remove(state.path)
+}
+
+// downloadSegment 下载单个分段
+func downloadSegment(file *os.File, url string, user *model.User, state *segmentState, seg *segment, tracker *progress.Tracker) error {
+	state.lock.Lock()
+	start := seg.Start + seg.Done
+	state.lock.Unlock()
+	if start > seg.End {
+		// 分段已经下载完成
+		return nil
+	}
+
+	jwt, cookies := userSnapshot(user)
+	rsp, err := doReq(url, GET, jwt, cookies, "", map[string]string{"Range": fmt.Sprintf("bytes=%d-%d", start, seg.End)})
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusPartialContent {
+		return errRangeNotSupported
+	}
+
+	w := &segmentWriter{w: io.NewOffsetWriter(file, start), state: state, seg: seg, tracker: tracker}
+	n, err := io.Copy(w, getDownloadLimiter(user).Reader(io.LimitReader(rsp.Body, seg.End-start+1)))
+	if err != nil {
+		return err
+	}
+	if n != seg.End-start+1 {
+		return fmt.Errorf("%w: 分段 %d-%d 期望 %d 字节, 实际 %d 字节", model.ErrVideoSizeMismatch, seg.Start, seg.End, seg.End-start+1, n)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/config"
+	"IwaraDownload/pkg/files"
+	"log"
+	"os"
+	"time"
+)
+
+// Liked 同步下载点赞的视频
+// 第一次运行时扫描全部点赞视频,扫描进度保存在目录中,中断后继续扫描
+// 全部扫描完成后只扫描到一整页都是已经记录过的视频为止
+func Liked(user *model.User) error {
+	filePath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.LIKED_DIR
+	if err := files.CheckDirOrCreate(filePath); err != nil {
+		return err
+	}
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := loadCheckpoint(filePath)
+	if err != nil {
+		return err
+	}
+
+	fullScan := len(db.VideoMap) == 0 || files.CheckFileExists(checkpointPath(filePath))
+	var startPage int
+	if fullScan {
+		// 点赞新的视频会导致列表后移,所以从进度页码的前一页开始扫描
+		startPage = max(checkpoint.Page-1, 0)
+		log.Println("扫描全部点赞视频, 从第", startPage, "页开始扫描")
+		if err := saveCheckpoint(filePath, checkpoint); err != nil {
+			log.Println("保存下载进度失败:", err)
+		}
+	}
+
+	var fullCount int
+	pool := newDownloadPool(user)
+	if fullScan {
+		pool.pages = newPageTracker(filePath, checkpoint)
+	}
+	scanErr := rangeListPage(startPage, func(page int) (*model.PageDataRoot, error) {
+		return request.GetLikedVideoData(user, page)
+	}, func(page int, pageData *model.PageDataRoot) (bool, error) {
+		if fullScan {
+			pool.pages.Scan(page)
+		}
+		known := true
+		for _, video := range pageData.Results {
+			if db.VideoMap[video.ID].Video == nil {
+				known = false
+			}
+			// 点赞的视频都需要下载,不检查下载条件
+			if pool.Queue(filePath, db, video, nil) {
+				fullCount++
+			}
+		}
+		if fullScan {
+			return false, nil
+		}
+		if known {
+			log.Println("当前页的视频都已经记录过,点赞视频扫描完成")
+		}
+		return known, nil
+	})
+
+	downloadCount := pool.Wait()
+	log.Println("本轮扫描一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	if scanErr != nil {
+		return scanErr
+	}
+	if fullScan {
+		if err := removeCheckpoint(filePath); err != nil {
+			log.Println("删除下载进度失败:", err)
+		}
+	}
+	return nil
+}
+
+func liked() {
+	log.Println("开始同步点赞视频")
+
+	retryTimes := 0
+	for {
+		start := time.Now()
+		log.Println("开始扫描点赞视频")
+		if err := Liked(config.Config); err != nil {
+			if retryTimes > consts.MAX_RETRY_TIMES {
+				log.Println("重试次数过多,程序退出")
+				os.Exit(1)
+			}
+			log.Println("扫描点赞视频任务失败", err, "开始重试")
+			retryTimes++
+			continue
+		}
+		log.Println("扫描点赞视频任务完成")
+		retryFailed(config.Config)
+		useTime := time.Since(start)
+		log.Println("本次扫描任务耗时:", useTime)
+		log.Println("===============================================================")
+		retryTimes = 0
+
+		if useTime < consts.SCAN_STEP {
+			time.Sleep(consts.SCAN_STEP - useTime)
+		}
+	}
+}
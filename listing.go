@@ -0,0 +1,63 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"log"
+	"os"
+	"strings"
+)
+
+// tagVideo 下载服务端按照标签筛选出的视频
+func tagVideo(user *model.User, tags []string) error {
+	log.Println("开始下载标签视频:", tags)
+	filePath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.TAG_DIR + string(os.PathSeparator) + files.SanitizeFileName(strings.Join(tags, ","))
+	return listing(user, filePath, func(page int) (*model.PageDataRoot, error) {
+		return request.GetTagVideoData(user, tags, page)
+	})
+}
+
+// searchVideo 下载服务端按照关键词搜索出的视频
+func searchVideo(user *model.User, query string) error {
+	log.Println("开始下载搜索视频:", query)
+	filePath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.SEARCH_DIR + string(os.PathSeparator) + files.SanitizeFileName(query)
+	return listing(user, filePath, func(page int) (*model.PageDataRoot, error) {
+		return request.SearchVideoData(user, query, page)
+	})
+}
+
+// listing 遍历服务端筛选后的视频列表并下载
+// 列表已经在服务端筛选过,所以只检查点赞数和禁止条件,不使用配置中指定的标签和作者
+func listing(user *model.User, filePath string, getPage func(page int) (*model.PageDataRoot, error)) error {
+	if err := files.CheckDirOrCreate(filePath); err != nil {
+		return err
+	}
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		return err
+	}
+
+	var fullCount int
+	pool := newDownloadPool(user)
+	scanErr := rangeListPage(0, getPage, func(page int, pageData *model.PageDataRoot) (bool, error) {
+		if page == 0 {
+			log.Println("一共找到", pageData.Count, "个视频")
+		}
+		for _, video := range pageData.Results {
+			if pool.Queue(filePath, db, video, skipBannedVideo) {
+				fullCount++
+			}
+		}
+		if consts.FlagConf.PageLimit > 0 && page+1 >= consts.FlagConf.PageLimit {
+			log.Println("已达到扫描页数限制:", consts.FlagConf.PageLimit)
+			return true, nil
+		}
+		return false, nil
+	})
+
+	downloadCount := pool.Wait()
+	log.Println("一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	return scanErr
+}
@@ -7,11 +7,11 @@ import (
 	"IwaraDownload/pkg/config"
 	"IwaraDownload/pkg/date"
 	"IwaraDownload/pkg/files"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/araddon/dateparse"
@@ -21,67 +21,82 @@ var (
 	maxPage int = 50 // 初始最大页数
 )
 
-// saveVideoDatabase 保存视频数据到本地数据库
-func saveVideoDatabase(filePath string, videoData model.Result, fileData []*model.Video) {
-	dataFileName := filePath + string(os.PathSeparator) + consts.VIDEO_DATABASE
+// monthDir 获取视频按照发布年月分类的下载目录
+func monthDir(year int, month int) string {
+	return consts.FlagConf.WorkDIr + string(os.PathSeparator) + strconv.Itoa(year) + string(os.PathSeparator) + strconv.Itoa(month)
+}
 
-	var db model.Data
-	// 检查文件是否存在
-	if files.CheckFileExists(dataFileName) {
-		// 读取文件
-		data, err := files.ReadFile(dataFileName)
-		if err != nil {
-			log.Println("读取数据库文件失败:", err)
-			return
-		}
-		// 解析文件
-		err = json.Unmarshal(data, &db)
-		if err != nil {
-			log.Println("解析数据库文件失败:", err)
-			return
-		}
-		// 添加新数据
-		if db.VideoMap == nil {
-			db.VideoMap = make(map[string]model.VideoData)
-		}
-	} else {
-		db = model.Data{
-			VideoMap: make(map[string]model.VideoData),
-		}
+// findVideoFile 查找目录中已经下载的视频文件,返回文件名和分辨率,兼容使用昵称命名的旧版本下载文件
+func findVideoFile(filePath string, video model.Result) (string, string) {
+	fileName, definition := files.FindVideoFile(files.SanitizeFileName(fmt.Sprintf("[%s] %s", video.User.Username, video.Title)), filePath)
+	if fileName == "" {
+		fileName, definition = files.FindVideoFile(files.SanitizeFileName(fmt.Sprintf("[%s] %s", video.User.Name, video.Title)), filePath)
 	}
+	return fileName, definition
+}
 
-	addData := model.VideoData{
-		Video: &videoData,
-		Files: fileData,
-	}
-	oldData, ok := db.VideoMap[videoData.ID]
-	if fileData == nil && ok {
-		// 如果没有文件数据,则不更新文件数据
-		addData.Files = oldData.Files
+// downloadVideo 获取视频下载地址并下载视频到指定目录,返回下载完成的视频文件路径
+func downloadVideo(user *model.User, filePath string, video model.Result) (string, error) {
+	videoUrl, err := request.GetVideoDownloadUrl(user, video)
+	if err != nil {
+		return "", fmt.Errorf("获取视频地址失败: %w", err)
 	}
-	db.VideoMap[videoData.ID] = addData
+	// 依据分辨率选择策略选择视频源
+	videoSrc := user.SelectVideoSource(video, videoUrl)
+	log.Printf("视频地址: %s\n", videoSrc.Src.Download)
+
+	// 保存视频数据到数据库
+	saveVideoDatabase(filePath, video, videoUrl)
 
-	// 保存数据库
-	data, err := json.Marshal(db)
+	videoName := files.SanitizeFileName(fmt.Sprintf("[%s] %s [%s].mp4", video.User.Username, video.Title, videoSrc.Name))
+	videoPath := filePath + string(os.PathSeparator) + videoName
+
+	// 检查磁盘剩余空间,不足时等待空间释放
+	release := acquireDiskSpace(user, videoPath, video.ExpectSize(videoSrc.Name))
+	defer release()
+
+	startDownloadTime := time.Now()
+	log.Printf("开始下载视频: %s 分辨率: %s\n", videoPath, videoSrc.Name)
+	err = request.Download(user, video, videoSrc, videoPath)
 	if err != nil {
-		log.Println("序列化数据库文件失败:", err)
-		return
+		return "", fmt.Errorf("下载视频失败: %s %w", videoName, err)
 	}
-	err = files.WriteFile(dataFileName, data)
-	if err != nil {
-		log.Println("写入数据库文件失败:", err)
-		return
+	log.Println("视频下载完成, 耗时:", time.Since(startDownloadTime))
+	saveVideoDownloaded(filePath, video.ID)
+
+	// 下载视频的附属文件
+	saveVideoExtras(user, videoPath, video, videoSrc)
+	return videoPath, nil
+}
+
+// saveVideoExtras 依据配置保存视频的附属文件
+func saveVideoExtras(user *model.User, videoPath string, video model.Result, videoSrc *model.Video) {
+	if user.EmbedMetadata {
+		embedMetadata(videoPath, video)
+	}
+	saveArtwork(user, videoPath, video)
+	if user.SaveNfo {
+		saveNfo(videoPath, video)
+	}
+	if user.SaveInfoJson {
+		// 信息文件中包含文件哈希,需要在修改视频文件之后生成
+		saveInfoJson(videoPath, video, videoSrc)
 	}
 }
 
 // skipVideo 依据配置检查是否跳过视频
 func skipVideo(user *model.User, video model.Result) bool {
+	return skipPost(user, video.User, video.Tags, video.NumLikes)
+}
+
+// skipPost 依据配置的作者,标签和点赞数检查是否跳过视频或图片帖子
+func skipPost(user *model.User, artist model.Artist, tags []model.Tag, likes int) bool {
 	var hasRules bool
 
 	// 检查点赞 (点赞是最顶级的优先度,如果设置了但是视频没有达到,那么不检查tag或者作者直接跳过)
-	if user.LikeLimit > 0 && video.NumLikes < user.LikeLimit {
+	if user.LikeLimit > 0 && likes < user.LikeLimit {
 		// 如果点赞数超过配置,则跳过当前视频
-		log.Printf("视频点赞数: %d,小于配置: %d,跳过当前视频\n", video.NumLikes, user.LikeLimit)
+		log.Printf("视频点赞数: %d,小于配置: %d,跳过当前视频\n", likes, user.LikeLimit)
 		return true
 	}
 
@@ -93,7 +108,7 @@ func skipVideo(user *model.User, video model.Result) bool {
 		for _, tag := range user.Tags {
 			tempVideoMap[tag] = true
 		}
-		for _, tag := range video.Tags {
+		for _, tag := range tags {
 			if tempVideoMap[tag.ID] {
 				// 如果标签在配置中,则跳过当前视频
 				log.Printf("视频标签: %s 在配置中,下载当前视频\n", tag.ID)
@@ -102,25 +117,44 @@ func skipVideo(user *model.User, video model.Result) bool {
 		}
 	}
 	// 2. 指定作者
-	if len(user.Artists) > 0 {
+	if artists := user.AllowedArtists(); len(artists) > 0 {
 		hasRules = true
-		for _, artist := range user.Artists {
-			if video.User.Username == artist {
+		for _, allowed := range artists {
+			if artist.Username == allowed {
 				// 如果作者在配置中,则跳过当前视频
-				log.Printf("视频作者: %s 在配置中,下载当前视频\n", artist)
+				log.Printf("视频作者: %s 在配置中,下载当前视频\n", allowed)
 				return false
 			}
 		}
 	}
 
 	// 再检查是否符合禁止条件进行跳过
+	if bannedPost(user, artist, tags) {
+		return true
+	}
+
+	// 如果一个指定条件都没有配置,则最后默认放行,如果配置了,则最后默认禁止
+	return hasRules
+}
+
+// skipBannedVideo 只依据点赞数和禁止条件检查是否跳过视频,用于已经在服务端按照标签或关键词筛选过的视频
+func skipBannedVideo(user *model.User, video model.Result) bool {
+	if user.LikeLimit > 0 && video.NumLikes < user.LikeLimit {
+		log.Printf("视频点赞数: %d,小于配置: %d,跳过当前视频\n", video.NumLikes, user.LikeLimit)
+		return true
+	}
+	return bannedPost(user, video.User, video.Tags)
+}
+
+// bannedPost 检查视频或图片帖子是否符合禁止条件
+func bannedPost(user *model.User, artist model.Artist, tags []model.Tag) bool {
 	// 1. 禁止标签
 	if len(user.BanTags) > 0 {
 		tempVideoMap := make(map[string]bool)
 		for _, tag := range user.BanTags {
 			tempVideoMap[tag] = true
 		}
-		for _, tag := range video.Tags {
+		for _, tag := range tags {
 			if tempVideoMap[tag.ID] {
 				// 如果标签在禁止列表中,则跳过当前视频
 				log.Printf("视频标签: %s 在禁止列表中,跳过当前视频\n", tag.ID)
@@ -131,18 +165,27 @@ func skipVideo(user *model.User, video model.Result) bool {
 	// 2. 禁止作者
 	if len(user.BanArtists) > 0 {
 		tempVideoMap := make(map[string]bool)
-		for _, artist := range user.BanArtists {
-			tempVideoMap[artist] = true
+		for _, banned := range user.BanArtists {
+			tempVideoMap[banned] = true
 		}
-		if tempVideoMap[video.User.Username] {
+		if tempVideoMap[artist.Username] {
 			// 如果作者在禁止列表中,则跳过当前视频
-			log.Printf("视频作者: %s 在禁止列表中,跳过当前视频\n", video.User.Username)
+			log.Printf("视频作者: %s 在禁止列表中,跳过当前视频\n", artist.Username)
 			return true
 		}
 	}
+	return false
+}
 
-	// 如果一个指定条件都没有配置,则最后默认放行,如果配置了,则最后默认禁止
-	return hasRules
+// parseCreateTime 解析接口返回的创建时间,转换为本地时区
+func parseCreateTime(createdAt string) (time.Time, error) {
+	createTime, err := dateparse.ParseLocal(createdAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 因为获取到的时间是标准时,但是转换库会将其转换为本地时区,所以需要重新修改回UTC后再次转换为本地时区
+	createTime = time.Date(createTime.Year(), createTime.Month(), createTime.Day(), createTime.Hour(), createTime.Minute(), createTime.Second(), createTime.Nanosecond(), time.UTC)
+	return createTime.Local(), nil
 }
 
 // rangePage 遍历页码
@@ -176,11 +219,33 @@ func rangePage(user *model.User, rangeFunc func(pageNum int, videoData model.Res
 	return nil
 }
 
+// rangeListPage 从指定页码开始遍历视频列表直到最后一页,每获取到一页数据调用一次 rangeFunc
+// rangeFunc 返回 Break 为 true 时停止遍历
+func rangeListPage(startPage int, getPage func(page int) (*model.PageDataRoot, error), rangeFunc func(page int, pageData *model.PageDataRoot) (Break bool, err error)) error {
+	for page := startPage; ; page++ {
+		log.Printf("正在获取第%d页视频列表\n", page)
+		pageData, err := getPage(page)
+		if err != nil {
+			log.Printf("获取视频列表失败: %s\n", err.Error())
+			return err
+		}
+		log.Println("视频列表获取成功")
+
+		Break, err := rangeFunc(page, pageData)
+		if err != nil {
+			return err
+		}
+		if Break || len(pageData.Results) == 0 || pageData.Limit <= 0 || (page+1)*pageData.Limit >= pageData.Count {
+			return nil
+		}
+	}
+}
+
 // Month 开始月下载任务
 func Month(user *model.User, year int, month int, lastDownloadTime time.Time) error {
 	log.Println("开始下载", year, "年", month, "月视频")
 
-	filePath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + strconv.Itoa(year) + string(os.PathSeparator) + strconv.Itoa(month)
+	filePath := monthDir(year, month)
 	err := files.CheckDirOrCreate(filePath)
 	if err != nil {
 		return err
@@ -191,8 +256,19 @@ func Month(user *model.User, year int, month int, lastDownloadTime time.Time) er
 	// 获取目标月份的最后一天
 	lastDayOfMonth := date.GetLastDayOfMonth(startTime)
 
-	var downloadCount int
+	// 被保留策略清理的视频不再重新下载
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		return err
+	}
+
 	var fullCount int
+	// 扫描到的视频放入下载队列,由下载协程并发下载,扫描结束后等待队列中的视频全部下载完成
+	pool := newDownloadPool(user)
+	defer func() {
+		downloadCount := pool.Wait()
+		log.Println("本轮扫描一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	}()
 
 	var videoDownload bool
 	for i := 0; i <= maxPage; i++ {
@@ -211,15 +287,12 @@ func Month(user *model.User, year int, month int, lastDownloadTime time.Time) er
 
 		for _, video := range pageData.Results {
 			log.Println("处理视频:", video.Title)
-			createTime, err := dateparse.ParseLocal(video.CreatedAt)
+			createTime, err := parseCreateTime(video.CreatedAt)
 			if err != nil {
 				log.Printf("解析时间失败: %s\n", err.Error())
 				// 跳过当前视频
 				continue
 			}
-			// 因为获取到的时间是标准时,但是转换库会将其转换为本地时区,所以需要重新修改回UTC后再次转换为本地时区
-			createTime = time.Date(createTime.Year(), createTime.Month(), createTime.Day(), createTime.Hour(), createTime.Minute(), createTime.Second(), createTime.Nanosecond(), time.UTC)
-			createTime = createTime.Local()
 			log.Println("视频创建时间:", createTime)
 
 			// 跳过不需要下载日期范围的视频
@@ -273,25 +346,19 @@ func Month(user *model.User, year int, month int, lastDownloadTime time.Time) er
 				log.Println("视频不符合下载条件,跳过...")
 				continue
 			}
+			if video.EmbedUrl != "" {
+				saveEmbedVideo(user, filePath, video)
+				continue
+			}
+			if db.VideoMap[video.ID].PrunedAt != nil {
+				log.Println("视频已被保留策略清理,跳过...")
+				continue
+			}
 			fullCount++
 
 			// 检查文件是否已经被下载,如果被下载则跳过
-			// 尝试从目标路径中获取可能的文件名
-			log.Printf("正在检查文件是否已下载, 作者: %s, 名称: %s", video.User.Username, video.Title)
-			checkName := files.SanitizeFileName(fmt.Sprintf("[%s] %s", video.User.Username, video.Title))
-			checkDir := filePath + string(os.PathSeparator)
-			f := files.CheckVideoFileExist(checkName, checkDir)
-			if f != "" {
+			if f, _ := findVideoFile(filePath, video); f != "" {
 				log.Printf("视频已存在: %s 跳过...\n", f)
-				continue
-			}
-			// 检查时会额外检查昵称(可更改的用户名),兼容旧版本下载数据(因为旧版本下载数据使用的是文件名是昵称,为了防止重复下载,特意添加的屎山容错)
-			log.Printf("(检查昵称)正在检查文件是否已下载, 作者: %s, 名称: %s", video.User.Name, video.Title)
-			checkName = files.SanitizeFileName(fmt.Sprintf("[%s] %s", video.User.Name, video.Title))
-			checkDir = filePath + string(os.PathSeparator)
-			f = files.CheckVideoFileExist(checkName, checkDir)
-			if f != "" {
-				log.Printf("(检查昵称)视频已存在: %s 跳过...\n", f)
 				// 保存视频数据到数据库
 				saveVideoDatabase(filePath, video, nil)
 				continue
@@ -300,103 +367,73 @@ func Month(user *model.User, year int, month int, lastDownloadTime time.Time) er
 
 			videoDownload = true
 
-			// 开始下载视频
-			videoUrl, err := request.GetVideoDownloadUrl(user, video)
-			if err != nil {
-				log.Printf("获取视频地址失败: %s\n", err.Error())
-				// 跳过当前视频
-				continue
-			}
-			log.Printf("视频地址: %s\n", videoUrl[0].Src.Download)
-
-			// 保存视频数据到数据库
-			saveVideoDatabase(filePath, video, videoUrl)
-
-			videoName := files.SanitizeFileName(fmt.Sprintf("[%s] %s [%s].mp4", video.User.Username, video.Title, videoUrl[0].Name))
-			videoPath := filePath + string(os.PathSeparator) + videoName
-			startDownloadTime := time.Now()
-			log.Printf("开始下载视频: %s 分辨率: %s\n", videoPath, videoUrl[0].Name)
-			err = request.Download(user, videoUrl[0].Src.Download, videoPath)
-			if err != nil {
-				log.Printf("下载视频失败: %s %s\n", videoName, err.Error())
-				// 跳过当前视频
-				continue
-			}
-			log.Println("视频下载完成, 耗时:", time.Since(startDownloadTime))
-			downloadCount++
+			// 加入下载队列
+			pool.Add(filePath, video)
 		}
-		request.DelaySwitch = videoDownload
+		request.SetDelaySwitch(videoDownload)
 	}
 
 	log.Println("视频下载任务完成")
-	log.Println("本轮扫描一共需要下载", fullCount, "个视频,本次下载", downloadCount)
 	return nil
 }
 
 // Hot 下载热门视频
+// 热门视频按照扫描日期保存在不同的目录中,每次扫描的热门排名作为快照记录在当天目录的数据库中
 func Hot(user *model.User, pageLimit int) error {
-	filePath := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.HOT_DIR
+	scanTime := time.Now()
+	filePath := hotBucketDir(scanTime)
 	err := files.CheckDirOrCreate(filePath)
 	if err != nil {
 		return err
 	}
-	var downloadCount int
 	var fullCount int
+	snapshot := model.HotSnapshot{Time: scanTime}
+	pool := newDownloadPool(user)
 	rangeErr := rangePage(user, func(pageNum int, video model.Result) (Break bool, page int, err error) {
 		if pageNum > pageLimit {
 			log.Println("热门视频下载任务完成")
 			return true, pageNum, nil
 		}
+		snapshot.Entries = append(snapshot.Entries, model.HotEntry{
+			ID:       video.ID,
+			Title:    video.Title,
+			Username: video.User.Username,
+			Rank:     len(snapshot.Entries) + 1,
+			Likes:    video.NumLikes,
+			Views:    video.NumViews,
+		})
 		log.Println("处理视频:", video.Title)
 		// 检查是否需要跳过当前视频
 		if skipVideo(user, video) {
 			log.Println("视频不符合下载条件,跳过...")
 			return false, pageNum, nil
 		}
+		if video.EmbedUrl != "" {
+			saveEmbedVideo(user, filePath, video)
+			return false, pageNum, nil
+		}
 		fullCount++
 		// 检查文件是否已经被下载,如果被下载则跳过
-		// 尝试从目标路径中获取可能的文件名
+		// 视频可能连续多天出现在热门列表中,需要检查最近几天的目录
 		log.Printf("正在检查文件是否已下载, 作者: %s, 名称: %s", video.User.Username, video.Title)
-		checkName := files.SanitizeFileName(fmt.Sprintf("[%s] %s", video.User.Username, video.Title))
-		checkDir := filePath + string(os.PathSeparator)
-		f := files.CheckVideoFileExist(checkName, checkDir)
+		dir, f := findHotVideoFile(scanTime, video)
 		if f != "" {
 			log.Printf("视频已存在: %s 跳过...\n", f)
 			// 保存视频数据到数据库
-			saveVideoDatabase(filePath, video, nil)
+			saveVideoDatabase(dir, video, nil)
 			return false, pageNum, nil
 		}
 		log.Println("文件不存在,准备获取视频下载地址")
 
-		// 开始下载视频
-		videoUrl, err := request.GetVideoDownloadUrl(user, video)
-		if err != nil {
-			log.Printf("获取视频地址失败: %s\n", err.Error())
-			// 跳过当前视频
-			return false, pageNum, nil
-		}
-		log.Printf("视频地址: %s\n", videoUrl[0].Src.Download)
-
-		// 保存视频数据到数据库
-		saveVideoDatabase(filePath, video, videoUrl)
-
-		videoName := files.SanitizeFileName(fmt.Sprintf("[%s] %s [%s].mp4", video.User.Username, video.Title, videoUrl[0].Name))
-		videoPath := filePath + string(os.PathSeparator) + videoName
-		startDownloadTime := time.Now()
-		log.Printf("开始下载视频: %s 分辨率: %s\n", videoPath, videoUrl[0].Name)
-		err = request.Download(user, videoUrl[0].Src.Download, videoPath)
-		if err != nil {
-			log.Printf("下载视频失败: %s %s\n", videoName, err.Error())
-			// 跳过当前视频
-			return false, pageNum, nil
-		}
-		log.Println("视频下载完成, 耗时:", time.Since(startDownloadTime))
-		downloadCount++
+		// 加入下载队列
+		pool.Add(filePath, video)
 
 		return false, pageNum, nil
 	})
 
+	downloadCount := pool.Wait()
 	log.Println("本轮扫描一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	saveHotSnapshot(filePath, snapshot)
 	return rangeErr
 }
 
@@ -409,6 +446,7 @@ func loop() {
 	for {
 		start := time.Now()
 		log.Println("开始扫描任务")
+		syncFollowing(config.Config)
 		// 获取当前年月
 		year := time.Now().Year()
 		month := time.Now().Month()
@@ -422,6 +460,9 @@ func loop() {
 			continue
 		}
 		log.Println("扫描任务完成")
+		retryFailed(config.Config)
+		refreshComments(config.Config)
+		retention(config.Config)
 		useTime := time.Since(start)
 		log.Println("本次扫描任务耗时:", useTime)
 		lastScanTime = start
@@ -452,6 +493,7 @@ func hot() {
 			continue
 		}
 		log.Println("下载热门视频任务完成")
+		retryFailed(config.Config)
 		useTime := time.Since(start)
 		log.Println("本次扫描任务耗时:", useTime)
 		log.Println("===============================================================")
@@ -486,9 +528,65 @@ func once() {
 
 // 流程为: 获取cookie -> 登录 -> 获取token -> 获取视频列表 -> 视频主页 -> 获取视频地址 -> 下载视频
 func main() {
-	if consts.FlagConf.Year != 0 || consts.FlagConf.Month != 0 {
+	if consts.FlagConf.Failed {
+		log.Println("指定了失败队列模式,列出下载失败的视频")
+		listFailed(config.Config)
+	} else if consts.FlagConf.ClearFailed {
+		log.Println("指定了清空失败队列模式,清空下载失败的视频")
+		clearFailed()
+	} else if consts.FlagConf.Nfo {
+		log.Println("指定了nfo模式,开始为已下载视频生成nfo文件")
+		backfillNfo()
+	} else if consts.FlagConf.Retag {
+		log.Println("指定了元数据模式,开始为已下载视频写入元数据")
+		backfillMetadata()
+	} else if consts.FlagConf.HotReport {
+		log.Println("指定了热门报告模式,开始统计热门视频")
+		hotReport()
+	} else if consts.FlagConf.Verify {
+		log.Println("指定了校验模式,开始校验已下载视频")
+		verify()
+	} else if consts.FlagConf.Artist != "" {
+		log.Println("指定了作者回溯模式,开始下载作者的全部视频")
+		config.Config.PrintLimit()
+		if err := artist(config.Config, consts.FlagConf.Artist); err != nil {
+			log.Println("作者回溯下载任务失败", err)
+		}
+	} else if consts.FlagConf.Playlist != "" {
+		log.Println("指定了播放列表模式,开始同步播放列表")
+		for _, input := range strings.Split(consts.FlagConf.Playlist, ",") {
+			if err := playlist(config.Config, input); err != nil {
+				log.Println("播放列表同步任务失败", err)
+			}
+		}
+	} else if consts.FlagConf.Tags != "" {
+		log.Println("指定了标签模式,开始下载标签视频")
+		if err := tagVideo(config.Config, strings.Split(consts.FlagConf.Tags, ",")); err != nil {
+			log.Println("标签视频下载任务失败", err)
+		}
+	} else if consts.FlagConf.Search != "" {
+		log.Println("指定了搜索模式,开始下载搜索视频")
+		if err := searchVideo(config.Config, consts.FlagConf.Search); err != nil {
+			log.Println("搜索视频下载任务失败", err)
+		}
+	} else if len(consts.FlagConf.Videos) > 0 || consts.FlagConf.List != "" {
+		log.Println("指定了视频地址,开始下载指定视频")
+		single(config.Config)
+	} else if consts.FlagConf.From != "" {
+		log.Println("指定了日期范围,开始下载日期范围内的视频")
+		config.Config.PrintLimit()
+		if err := Backfill(config.Config, consts.FlagConf.From, consts.FlagConf.To); err != nil {
+			log.Println("日期范围下载任务失败", err)
+		}
+	} else if consts.FlagConf.Year != 0 || consts.FlagConf.Month != 0 {
 		log.Println("指定了年份或月份,开始下载指定月份视频")
 		once()
+	} else if consts.FlagConf.Liked {
+		log.Println("指定了点赞模式,开始同步点赞视频")
+		liked()
+	} else if consts.FlagConf.Images {
+		log.Println("指定了图片模式,开始下载图片帖子")
+		images()
 	} else if config.Config.Hot {
 		log.Println("指定了热门视频模式,开始下载热门视频")
 		hot()
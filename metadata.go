@@ -0,0 +1,41 @@
+package main
+
+import (
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/mp4"
+	"log"
+	"time"
+)
+
+// embedMetadata 将视频信息写入视频文件的元数据
+func embedMetadata(videoPath string, video model.Result) {
+	if err := mp4.WriteMetadata(videoPath, mp4.NewMetadata(video)); err != nil {
+		log.Println("写入视频元数据失败:", video.Title, err)
+		return
+	}
+	log.Println("视频元数据写入完成:", videoPath)
+}
+
+// backfillMetadata 为数据库中记录的所有已下载视频写入元数据,已经写入相同元数据的视频会跳过
+func backfillMetadata() {
+	start := time.Now()
+	var count int
+	err := rangeDownloadedVideo(func(filePath string, videoPath string, data model.VideoData) {
+		meta := mp4.NewMetadata(*data.Video)
+		old, err := mp4.ReadMetadata(videoPath)
+		if err != nil {
+			log.Println("读取视频元数据失败:", videoPath, err)
+			return
+		}
+		if old == meta {
+			return
+		}
+		embedMetadata(videoPath, *data.Video)
+		count++
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+	log.Println("视频元数据写入任务完成, 一共写入", count, "个视频")
+	log.Println("本次任务耗时:", time.Since(start))
+}
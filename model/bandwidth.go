@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	scheduleTimeLayout = "15:04" // 限速时间段的时间格式
+)
+
+// BandwidthSchedule 限速时间段,在 [Start, End) 时间段内使用指定的限速值
+type BandwidthSchedule struct {
+	Start string `json:"start"` // 开始时间,格式为 15:04
+	End   string `json:"end"`   // 结束时间,格式为 15:04,早于开始时间表示跨越零点
+	Limit int    `json:"limit"` // 限速值(KB/s),0表示不限速
+}
+
+// Check 检查限速时间段配置
+func (s BandwidthSchedule) Check() error {
+	if _, err := time.Parse(scheduleTimeLayout, s.Start); err != nil {
+		return ErrBandwidthSchedule
+	}
+	if _, err := time.Parse(scheduleTimeLayout, s.End); err != nil {
+		return ErrBandwidthSchedule
+	}
+	if s.Limit < 0 {
+		return ErrBandwidthSchedule
+	}
+	return nil
+}
+
+// Contains 检查指定时间是否在限速时间段内
+func (s BandwidthSchedule) Contains(t time.Time) bool {
+	start, err := time.Parse(scheduleTimeLayout, s.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(scheduleTimeLayout, s.End)
+	if err != nil {
+		return false
+	}
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	minute := t.Hour()*60 + t.Minute()
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	// 跨越零点的时间段
+	return minute >= startMinute || minute < endMinute
+}
+
+// CurrentBandwidthLimit 获取指定时间的下载限速值(字节/秒),0表示不限速
+// 优先使用匹配的限速时间段,没有匹配时使用全局限速
+func (u *User) CurrentBandwidthLimit(t time.Time) int64 {
+	for _, schedule := range u.BandwidthSchedules {
+		if schedule.Contains(t) {
+			return int64(schedule.Limit) * 1024
+		}
+	}
+	if u.BandwidthLimit > 0 {
+		return int64(u.BandwidthLimit) * 1024
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCurrentBandwidthLimit tests the CurrentBandwidthLimit function
+func TestCurrentBandwidthLimit(t *testing.T) {
+	user := &User{
+		BandwidthLimit: 2048,
+		BandwidthSchedules: []BandwidthSchedule{
+			{Start: "01:00", End: "07:00", Limit: 0},
+			{Start: "22:00", End: "00:30", Limit: 512},
+		},
+	}
+
+	tests := []struct {
+		name string
+		time time.Time
+		want int64
+	}{
+		{
+			name: "inside full speed window",
+			time: time.Date(2024, 1, 1, 3, 0, 0, 0, time.Local),
+			want: 0,
+		},
+		{
+			name: "window end is exclusive",
+			time: time.Date(2024, 1, 1, 7, 0, 0, 0, time.Local),
+			want: 2048 * 1024,
+		},
+		{
+			name: "window across midnight before zero",
+			time: time.Date(2024, 1, 1, 23, 0, 0, 0, time.Local),
+			want: 512 * 1024,
+		},
+		{
+			name: "window across midnight after zero",
+			time: time.Date(2024, 1, 1, 0, 15, 0, 0, time.Local),
+			want: 512 * 1024,
+		},
+		{
+			name: "outside windows uses global limit",
+			time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local),
+			want: 2048 * 1024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, user.CurrentBandwidthLimit(tt.time))
+		})
+	}
+}
+
+// TestBandwidthScheduleCheck tests the Check function
+func TestBandwidthScheduleCheck(t *testing.T) {
+	assert.NoError(t, BandwidthSchedule{Start: "01:00", End: "07:00", Limit: 100}.Check())
+	assert.Error(t, BandwidthSchedule{Start: "1am", End: "07:00", Limit: 100}.Check())
+	assert.Error(t, BandwidthSchedule{Start: "01:00", End: "07:00", Limit: -1}.Check())
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Checkpoint 长时间回溯下载任务的进度,用于中断后继续下载
+type Checkpoint struct {
+	Page      int       `json:"page"`           // 最小的还有视频没有下载完成的页码
+	From      string    `json:"from,omitempty"` // 日期范围回溯的开始月份,用于判断进度是否属于同一个任务
+	To        string    `json:"to,omitempty"`   // 日期范围回溯的结束月份
+	UpdatedAt time.Time `json:"updatedAt"`      // 进度更新时间
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Comment 结构体表示视频评论
+type Comment struct {
+	ID         string     `json:"id"`
+	Body       string     `json:"body"`
+	User       Artist     `json:"user"`
+	NumReplies int        `json:"numReplies"`
+	CreatedAt  string     `json:"createdAt"`
+	UpdatedAt  string     `json:"updatedAt"`
+	Replies    []*Comment `json:"replies,omitempty"` // 评论的回复,由程序获取后填充
+}
+
+// CommentPageRoot 结构体表示评论分页数据
+type CommentPageRoot struct {
+	Count   int        `json:"count"`
+	Limit   int        `json:"limit"`
+	Page    int        `json:"page"`
+	Results []*Comment `json:"results"`
+}
+
+// CommentArchive 保存到视频旁边的评论存档
+type CommentArchive struct {
+	VideoID   string     `json:"videoId"`   // 视频ID
+	FetchedAt time.Time  `json:"fetchedAt"` // 获取评论的时间
+	Count     int        `json:"count"`     // 评论总数(包含回复)
+	Comments  []*Comment `json:"comments"`  // 评论列表
+}
@@ -1,12 +1,42 @@
 package model
 
+import "time"
+
+const (
+	VideoStateEmbedded = "embedded" // 嵌入的外部视频,无法直接下载
+	VideoStateRemoved  = "removed"  // 已经从播放列表中移除的视频
+)
+
 // VideoData 视频数据
 type VideoData struct {
-	Video *Result
-	Files []*Video
+	Video        *Result
+	Files        []*Video
+	PrunedAt     *time.Time // 被保留策略清理的时间,为空表示没有被清理
+	State        string     // 视频状态,为空表示普通视频
+	DownloadedAt *time.Time // 视频下载完成的时间
+}
+
+// FailedData 下载失败的视频数据
+type FailedData struct {
+	Video       *Result
+	Reason      string    // 最后一次失败的原因
+	Attempts    int       // 失败次数
+	LastAttempt time.Time // 最后一次尝试下载的时间
+	NextAttempt time.Time // 下次重试的时间
+}
+
+// ImageData 图片帖子数据
+type ImageData struct {
+	Image        *Image
+	Files        []string   // 已经下载的图片文件名
+	DownloadedAt *time.Time // 帖子中的图片全部下载完成的时间
 }
 
 // Data 存储视频数据
 type Data struct {
-	VideoMap map[string]VideoData
+	VideoMap  map[string]VideoData
+	FailedMap map[string]FailedData // 下载失败等待重试的视频
+	ImageMap  map[string]ImageData  // 图片帖子
+
+	HotSnapshots []HotSnapshot `json:",omitempty"` // 热门模式每次扫描的热门列表快照
 }
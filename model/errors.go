@@ -18,4 +18,25 @@ var (
 	ErrNoVideoUrl              = E{4, "没有视频地址"}
 	ErrEmptyUsernameOrPassword = E{5, "用户名或密码为空"}
 	ErrTokenMalformed          = E{6, "token格式错误"}
+	ErrVideoSizeMismatch       = E{7, "视频文件大小不匹配"}
+	ErrVideoTruncated          = E{8, "视频文件不完整"}
+	ErrVideoInvalid            = E{9, "视频文件结构错误"}
+	ErrBandwidthSchedule       = E{10, "限速时间段配置错误"}
+	ErrResolution              = E{11, "分辨率配置错误"}
+	ErrEmbedVideo              = E{12, "嵌入的外部视频无法直接下载"}
 )
+
+// EmbedVideoError 获取视频详情后才发现是嵌入的外部视频,携带嵌入地址
+type EmbedVideoError struct {
+	EmbedUrl string // 嵌入的外部视频地址
+}
+
+// Error 实现error接口
+func (e *EmbedVideoError) Error() string {
+	return ErrEmbedVideo.Msg + ": " + e.EmbedUrl
+}
+
+// Unwrap 兼容使用 ErrEmbedVideo 判断错误
+func (e *EmbedVideoError) Unwrap() error {
+	return ErrEmbedVideo
+}
@@ -0,0 +1,25 @@
+package model
+
+// Favorite 结构体表示点赞的视频
+type Favorite struct {
+	ID        string `json:"id"`
+	Video     Result `json:"video"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// FavoritePageRoot 结构体表示点赞列表分页数据
+type FavoritePageRoot struct {
+	Count   int        `json:"count"`
+	Limit   int        `json:"limit"`
+	Page    int        `json:"page"`
+	Results []Favorite `json:"results"`
+}
+
+// PageData 转换为视频列表分页数据
+func (f *FavoritePageRoot) PageData() *PageDataRoot {
+	pageData := &PageDataRoot{Count: f.Count, Limit: f.Limit, Page: f.Page}
+	for _, favorite := range f.Results {
+		pageData.Results = append(pageData.Results, favorite.Video)
+	}
+	return pageData
+}
@@ -0,0 +1,47 @@
+package model
+
+import "slices"
+
+// Follow 结构体表示关注的用户
+type Follow struct {
+	User      Artist `json:"user"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// FollowPageRoot 结构体表示关注列表分页数据
+type FollowPageRoot struct {
+	Count   int      `json:"count"`
+	Limit   int      `json:"limit"`
+	Page    int      `json:"page"`
+	Results []Follow `json:"results"`
+}
+
+// AllowedArtists 获取实际生效的下载作者,包含配置中指定的作者和同步的关注列表
+// 关注列表只扩大已经配置的下载范围,没有配置下载标签和作者时依然下载全部视频
+func (u *User) AllowedArtists() []string {
+	artists := slices.Clone(u.Artists)
+	if !u.SyncFollowing || (len(u.Artists) == 0 && len(u.Tags) == 0) {
+		return artists
+	}
+	for _, artist := range u.FollowingArtists {
+		if !slices.Contains(artists, artist) {
+			artists = append(artists, artist)
+		}
+	}
+	return artists
+}
+
+// DiffArtists 比较新旧作者列表,返回新增和移除的作者
+func DiffArtists(oldArtists []string, newArtists []string) (added []string, removed []string) {
+	for _, artist := range newArtists {
+		if !slices.Contains(oldArtists, artist) {
+			added = append(added, artist)
+		}
+	}
+	for _, artist := range oldArtists {
+		if !slices.Contains(newArtists, artist) {
+			removed = append(removed, artist)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAllowedArtists tests the AllowedArtists method
+func TestAllowedArtists(t *testing.T) {
+	user := &User{SyncFollowing: true, Artists: []string{"a", "b"}, FollowingArtists: []string{"b", "c"}}
+	assert.Equal(t, []string{"a", "b", "c"}, user.AllowedArtists())
+	assert.Equal(t, []string{"a", "b"}, user.Artists)
+
+	// 没有开启同步时不使用关注列表
+	user.SyncFollowing = false
+	assert.Equal(t, []string{"a", "b"}, user.AllowedArtists())
+
+	// 没有配置下载标签和作者时关注列表不会缩小下载范围
+	user = &User{SyncFollowing: true, FollowingArtists: []string{"c"}}
+	assert.Empty(t, user.AllowedArtists())
+	user.Tags = []string{"tag"}
+	assert.Equal(t, []string{"c"}, user.AllowedArtists())
+
+	assert.Empty(t, (&User{}).AllowedArtists())
+}
+
+// TestDiffArtists tests the DiffArtists function
+func TestDiffArtists(t *testing.T) {
+	added, removed := DiffArtists([]string{"a", "b"}, []string{"b", "c"})
+	assert.Equal(t, []string{"c"}, added)
+	assert.Equal(t, []string{"a"}, removed)
+
+	added, removed = DiffArtists(nil, nil)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
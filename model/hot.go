@@ -0,0 +1,85 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// HotEntry 热门列表中的单个视频
+type HotEntry struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+	Rank     int    `json:"rank"` // 在热门列表中的排名,从1开始
+	Likes    int    `json:"likes"`
+	Views    int    `json:"views"`
+}
+
+// HotSnapshot 一次热门扫描的快照
+type HotSnapshot struct {
+	Time    time.Time  `json:"time"`
+	Entries []HotEntry `json:"entries"`
+}
+
+// HotStat 视频在热门列表中的统计数据
+type HotStat struct {
+	ID        string
+	Title     string
+	Username  string
+	Days      int       // 出现在热门列表中的天数
+	Scans     int       // 出现在热门列表中的扫描次数
+	BestRank  int       // 最高排名
+	FirstSeen time.Time // 第一次出现的时间
+	LastSeen  time.Time // 最后一次出现的时间
+	Likes     int       // 最后一次出现时的点赞数
+	Views     int       // 最后一次出现时的播放数
+}
+
+// HotStats 统计快照中每个视频在热门列表中停留的时间,按照停留天数,扫描次数和最高排名排序
+func HotStats(snapshots []HotSnapshot) []*HotStat {
+	statMap := make(map[string]*HotStat)
+	dayMap := make(map[string]map[string]bool) // 视频出现过的日期
+	for _, snapshot := range snapshots {
+		day := snapshot.Time.Local().Format(time.DateOnly)
+		for _, entry := range snapshot.Entries {
+			stat, ok := statMap[entry.ID]
+			if !ok {
+				stat = &HotStat{ID: entry.ID, BestRank: entry.Rank, FirstSeen: snapshot.Time}
+				statMap[entry.ID] = stat
+				dayMap[entry.ID] = make(map[string]bool)
+			}
+			stat.Scans++
+			stat.BestRank = min(stat.BestRank, entry.Rank)
+			if snapshot.Time.Before(stat.FirstSeen) {
+				stat.FirstSeen = snapshot.Time
+			}
+			if !snapshot.Time.Before(stat.LastSeen) {
+				stat.LastSeen = snapshot.Time
+				stat.Title = entry.Title
+				stat.Username = entry.Username
+				stat.Likes = entry.Likes
+				stat.Views = entry.Views
+			}
+			dayMap[entry.ID][day] = true
+		}
+	}
+
+	stats := make([]*HotStat, 0, len(statMap))
+	for id, stat := range statMap {
+		stat.Days = len(dayMap[id])
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Days != stats[j].Days {
+			return stats[i].Days > stats[j].Days
+		}
+		if stats[i].Scans != stats[j].Scans {
+			return stats[i].Scans > stats[j].Scans
+		}
+		if stats[i].BestRank != stats[j].BestRank {
+			return stats[i].BestRank < stats[j].BestRank
+		}
+		return stats[i].ID < stats[j].ID
+	})
+	return stats
+}
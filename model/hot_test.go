@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHotStats tests the HotStats function
+func TestHotStats(t *testing.T) {
+	day1 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	snapshots := []HotSnapshot{
+		{Time: day1, Entries: []HotEntry{{ID: "a", Title: "A", Rank: 3, Likes: 10}, {ID: "b", Title: "B", Rank: 1}}},
+		{Time: day1.Add(time.Hour), Entries: []HotEntry{{ID: "a", Title: "A", Rank: 2, Likes: 20}, {ID: "b", Title: "B", Rank: 1}}},
+		{Time: day2, Entries: []HotEntry{{ID: "a", Title: "A2", Rank: 5, Likes: 30}, {ID: "c", Title: "C", Rank: 1}}},
+	}
+
+	stats := HotStats(snapshots)
+	assert.Len(t, stats, 3)
+
+	assert.Equal(t, "a", stats[0].ID)
+	assert.Equal(t, 2, stats[0].Days)
+	assert.Equal(t, 3, stats[0].Scans)
+	assert.Equal(t, 2, stats[0].BestRank)
+	assert.Equal(t, "A2", stats[0].Title)
+	assert.Equal(t, 30, stats[0].Likes)
+	assert.Equal(t, day1, stats[0].FirstSeen)
+	assert.Equal(t, day2, stats[0].LastSeen)
+
+	// 天数相同时扫描次数多的在前
+	assert.Equal(t, "b", stats[1].ID)
+	assert.Equal(t, 2, stats[1].Scans)
+	assert.Equal(t, "c", stats[2].ID)
+
+	assert.Empty(t, HotStats(nil))
+}
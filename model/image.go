@@ -0,0 +1,30 @@
+package model
+
+// Image 结构体表示图片帖子信息
+type Image struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	Status      string `json:"status"`
+	Rating      string `json:"rating"`
+	Liked       bool   `json:"liked"`
+	NumImages   int    `json:"numImages"`
+	NumLikes    int    `json:"numLikes"`
+	NumViews    int    `json:"numViews"`
+	NumComments int    `json:"numComments"`
+	Thumbnail   *File  `json:"thumbnail"`
+	Files       []File `json:"files"` // 帖子中的全部图片,只有帖子详情中才有
+	User        Artist `json:"user"`
+	Tags        []Tag  `json:"tags"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+// ImagePageRoot 结构体表示图片列表分页数据
+type ImagePageRoot struct {
+	Count   int     `json:"count"`
+	Limit   int     `json:"limit"`
+	Page    int     `json:"page"`
+	Results []Image `json:"results"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	VideoPageUrl = "https://www.iwara.tv/video/%s" // 视频页面地址
+)
+
+// DownloadInfo 视频的下载信息
+type DownloadInfo struct {
+	Time       time.Time `json:"time"`       // 下载完成时间
+	Resolution string    `json:"resolution"` // 下载的分辨率
+	Size       int64     `json:"size"`       // 文件大小
+	SHA256     string    `json:"sha256"`     // 文件哈希
+}
+
+// InfoJson 视频旁边的信息文件,字段命名参考 yt-dlp 的 info.json
+type InfoJson struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Uploader     string   `json:"uploader"`
+	UploaderID   string   `json:"uploader_id"`
+	WebpageUrl   string   `json:"webpage_url"`
+	UploadDate   string   `json:"upload_date"` // 格式为 20060102
+	Timestamp    int64    `json:"timestamp"`
+	Tags         []string `json:"tags"`
+	Duration     int      `json:"duration"`
+	ViewCount    int      `json:"view_count"`
+	LikeCount    int      `json:"like_count"`
+	CommentCount int      `json:"comment_count"`
+	Thumbnail    string   `json:"thumbnail"`
+	FormatID     string   `json:"format_id"`
+	Width        int      `json:"width"`
+	Height       int      `json:"height"`
+	Filesize     int64    `json:"filesize"`
+	Filename     string   `json:"_filename"`
+	Extractor    string   `json:"extractor"`
+
+	Video    Result       `json:"iwara"`    // 完整的视频数据
+	Source   *Video       `json:"source"`   // 下载使用的视频源
+	Download DownloadInfo `json:"download"` // 下载信息
+}
+
+// NewInfoJson 使用视频数据和下载信息生成信息文件
+func NewInfoJson(video Result, src *Video, fileName string, download DownloadInfo) InfoJson {
+	info := InfoJson{
+		ID:           video.ID,
+		Title:        video.Title,
+		Description:  video.Body,
+		Uploader:     video.User.Name,
+		UploaderID:   video.User.Username,
+		WebpageUrl:   fmt.Sprintf(VideoPageUrl, video.ID),
+		Duration:     video.File.Duration,
+		ViewCount:    video.NumViews,
+		LikeCount:    video.NumLikes,
+		CommentCount: video.NumComments,
+		Filesize:     download.Size,
+		Filename:     fileName,
+		Extractor:    "iwara",
+		Video:        video,
+		Source:       src,
+		Download:     download,
+	}
+	for _, tag := range video.Tags {
+		info.Tags = append(info.Tags, tag.ID)
+	}
+	if createTime, err := time.Parse(time.RFC3339, video.CreatedAt); err == nil {
+		info.UploadDate = createTime.UTC().Format("20060102")
+		info.Timestamp = createTime.Unix()
+	}
+	if src != nil {
+		info.FormatID = src.Name
+		// 只有源文件的分辨率是已知的
+		if src.Name == "Source" {
+			info.Width = video.File.Width
+			info.Height = video.File.Height
+		}
+	}
+	return info
+}
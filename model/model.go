@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,14 +28,50 @@ type User struct {
 	Tags    []string `json:"tags"`    // 下载指定标签
 	Artists []string `json:"artists"` // 下载指定用户的内容
 
+	SyncFollowing    bool     `json:"syncFollowing"`              // 每轮扫描时同步账号的关注列表,配置了下载标签或作者时关注的作者也视为下载作者
+	SaveFollowing    bool     `json:"saveFollowing"`              // 关注列表变化时保存到配置文件,重启后同步失败时继续使用
+	SavedFollowing   []string `json:"followingArtists,omitempty"` // 保存到配置文件的关注作者,只在开启 saveFollowing 时写入
+	FollowingArtists []string `json:"-"`                          // 同步得到的关注作者,由程序维护
+
 	BanArtists []string `json:"banArtists"` // 禁止下载指定用户的内容
 	BanTags    []string `json:"banTags"`    // 跳过标签
 	LikeLimit  int      `json:"likeLimit"`  // 下载达到目标点赞数量的视频
 	// ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑ 下载条件 ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑
 
+	// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓ 下载设置 ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+	DownloadWorkers  int `json:"downloadWorkers"`  // 同时下载的视频数量
+	DownloadSegments int `json:"downloadSegments"` // 单个视频分段下载的连接数量,大于1时开启分段下载
+	MaxDownloadRetry int `json:"maxDownloadRetry"` // 下载失败的视频最大重试次数
+	ProgressInterval int `json:"progressInterval"` // 下载进度的输出间隔(秒),小于0表示不输出
+	DiskReserve      int `json:"diskReserve"`      // 下载时磁盘需要额外保留的剩余空间(MB)
+
+	Retention RetentionPolicy `json:"retention"` // 年/月目录中视频的保留策略
+
+	Resolution          ResolutionPolicy     `json:"resolution"`          // 分辨率选择策略
+	ResolutionOverrides []ResolutionOverride `json:"resolutionOverrides"` // 指定作者或标签的分辨率选择策略,优先于默认策略
+
+	BandwidthLimit     int                 `json:"bandwidthLimit"`     // 下载总限速(KB/s),0表示不限速
+	BandwidthSchedules []BandwidthSchedule `json:"bandwidthSchedules"` // 按时间段设置的下载限速,优先于总限速
+
+	MarkPlaylistRemoved bool `json:"markPlaylistRemoved"` // 播放列表模式下在数据库中标记已经从播放列表中移除的视频
+	// ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑ 下载设置 ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑
+
+	// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓ 附属文件 ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
+	SaveThumbnail bool `json:"saveThumbnail"` // 在视频旁边保存缩略图海报
+	SaveAvatar    bool `json:"saveAvatar"`    // 保存作者头像
+	SaveNfo       bool `json:"saveNfo"`       // 在视频旁边生成 Kodi/Jellyfin 使用的nfo文件
+	EmbedMetadata bool `json:"embedMetadata"` // 将标题,作者,标签和视频地址写入视频文件的元数据
+	SaveInfoJson  bool `json:"saveInfoJson"`  // 在视频旁边保存包含完整视频数据和下载信息的 .info.json 文件
+	ExportEmbed   bool `json:"exportEmbed"`   // 将嵌入的外部视频地址导出到列表文件,方便使用其他工具下载
+
+	ArchiveComments    bool `json:"archiveComments"`    // 本轮下载全部完成后在视频旁边保存视频评论
+	CommentRefreshDays int  `json:"commentRefreshDays"` // 下载后多少天内的视频会在扫描时重新获取评论,0表示不重新获取
+	// ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑ 附属文件 ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑
+
 	// ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓ 临时数据 ↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓↓
 	Cookies       []*http.Cookie `json:"-"` // cookie
 	authorization string         // 当前使用的jwt
+	authLock      sync.RWMutex   // 登录信息读写锁,文件下载协程会同时读取jwt和cookie
 	// ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑ 临时数据 ↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑↑
 }
 
@@ -57,6 +94,12 @@ func (u *User) PrintLimit() {
 		hasRules = true
 		log.Printf("下载用户: %#v", u.Artists)
 	}
+	if u.SyncFollowing && len(u.Tags) == 0 && len(u.Artists) == 0 {
+		log.Println("没有配置下载标签和作者,同步的关注列表不会生效,依然下载全部视频")
+	}
+	if following := u.AllowedArtists()[len(u.Artists):]; len(following) > 0 {
+		log.Printf("关注用户: %#v", following)
+	}
 	if len(u.BanTags) > 0 {
 		hasRules = true
 		log.Printf("ban标签: %#v", u.BanTags)
@@ -73,10 +116,53 @@ func (u *User) PrintLimit() {
 	if !hasRules {
 		log.Println("没有设置下载条件,下载所有视频")
 	}
+
+	if u.Resolution.Preferred != "" {
+		log.Printf("首选分辨率: %s 备选分辨率: %#v", u.Resolution.Preferred, u.Resolution.Fallback)
+	}
+	if u.Resolution.Max != "" {
+		log.Printf("最高分辨率: %s", u.Resolution.Max)
+	}
+	for _, override := range u.ResolutionOverrides {
+		log.Printf("作者: %#v 标签: %#v 使用分辨率策略: %+v", override.Artists, override.Tags, override.ResolutionPolicy)
+	}
+
+	if u.Retention.MaxTotalSize > 0 {
+		log.Printf("视频最大总大小: %d GB", u.Retention.MaxTotalSize)
+	}
+	if u.Retention.MaxAge > 0 {
+		log.Printf("视频最大保留天数: %d", u.Retention.MaxAge)
+	}
+
+	if u.BandwidthLimit > 0 {
+		log.Printf("下载限速: %d KB/s", u.BandwidthLimit)
+	}
+	for _, schedule := range u.BandwidthSchedules {
+		if schedule.Limit > 0 {
+			log.Printf("%s - %s 下载限速: %d KB/s", schedule.Start, schedule.End, schedule.Limit)
+		} else {
+			log.Printf("%s - %s 不限速", schedule.Start, schedule.End)
+		}
+	}
+}
+
+// RetentionPolicy 视频保留策略,超出限制时从最旧的视频开始清理
+type RetentionPolicy struct {
+	MaxTotalSize  int    `json:"maxTotalSize"`  // 视频最大总大小(GB),0表示不限制
+	MaxAge        int    `json:"maxAge"`        // 视频最大保留天数,从视频所在月份的最后一天开始计算,0表示不限制
+	KeepWhitelist bool   `json:"keepWhitelist"` // 是否永久保留 tags 和 artists 中指定的视频
+	MoveTo        string `json:"moveTo"`        // 清理的视频移动到该目录,为空时直接删除
 }
 
 // GetAuthorization 获取当前使用的jwt
 func (u *User) GetAuthorization() string {
+	u.authLock.RLock()
+	defer u.authLock.RUnlock()
+	return u.getAuthorization()
+}
+
+// getAuthorization 获取当前使用的jwt,调用方需要持有 authLock
+func (u *User) getAuthorization() string {
 	if u.authorization == "" {
 		return ""
 	}
@@ -85,9 +171,25 @@ func (u *User) GetAuthorization() string {
 
 // SetAuthorization 设置当前使用的jwt
 func (u *User) SetAuthorization(jwt string) {
+	u.authLock.Lock()
+	defer u.authLock.Unlock()
 	u.authorization = jwt
 }
 
+// SetCookies 设置当前使用的cookie
+func (u *User) SetCookies(cookies []*http.Cookie) {
+	u.authLock.Lock()
+	defer u.authLock.Unlock()
+	u.Cookies = cookies
+}
+
+// AuthSnapshot 获取当前使用的jwt和cookie的快照
+func (u *User) AuthSnapshot() (string, []*http.Cookie) {
+	u.authLock.RLock()
+	defer u.authLock.RUnlock()
+	return u.getAuthorization(), u.Cookies
+}
+
 // Check 检查用户信息
 func (u *User) Check() error {
 	if u.Username == "" || u.Password == "" {
@@ -138,7 +240,9 @@ func (u *User) SetAccessToken(rspBody []byte) error {
 	if err != nil {
 		return err
 	}
+	u.authLock.Lock()
 	u.AccessToken = accessToken.AccessToken
+	u.authLock.Unlock()
 	_, err = NewAccessTokenJwt(u.AccessToken)
 	if err != nil {
 		return err
@@ -170,6 +274,15 @@ func (u *User) CheckAccessToken() bool {
 	return time.Now().Before(jwtData.ExpiresAt.Time)
 }
 
+// UserID 从访问token中获取当前登录账号的用户ID
+func (u *User) UserID() (string, error) {
+	jwtData, err := NewAccessTokenJwt(u.AccessToken)
+	if err != nil {
+		return "", err
+	}
+	return jwtData.ID, nil
+}
+
 // CustomClaims 自定义jwt
 type CustomClaims struct {
 	ID      string `json:"id"`
@@ -0,0 +1,17 @@
+package model
+
+// Playlist 结构体表示播放列表信息
+type Playlist struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	User      Artist `json:"user"`
+	NumVideos int    `json:"numVideos"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// PlaylistPageRoot 结构体表示播放列表分页数据
+type PlaylistPageRoot struct {
+	PageDataRoot
+	Playlist Playlist `json:"playlist"`
+}
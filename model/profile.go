@@ -0,0 +1,9 @@
+package model
+
+// Profile 结构体表示用户主页信息
+type Profile struct {
+	User      Artist `json:"user"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
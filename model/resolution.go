@@ -0,0 +1,96 @@
+package model
+
+// ResolutionPolicy 分辨率选择策略,所有字段都为空时下载最高分辨率
+type ResolutionPolicy struct {
+	Preferred string   `json:"preferred"` // 首选分辨率
+	Max       string   `json:"max"`       // 最高分辨率,超过该分辨率的视频源不会被选择
+	Fallback  []string `json:"fallback"`  // 首选分辨率不存在时依次尝试的分辨率
+}
+
+// ResolutionOverride 指定作者或标签使用的分辨率选择策略
+type ResolutionOverride struct {
+	Artists []string `json:"artists"` // 匹配的作者
+	Tags    []string `json:"tags"`    // 匹配的标签
+	ResolutionPolicy
+}
+
+// Check 检查分辨率选择策略配置
+func (p ResolutionPolicy) Check() error {
+	names := append([]string{p.Preferred, p.Max}, p.Fallback...)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, ok := VideoDefinitionMap[name]; !ok {
+			return ErrResolution
+		}
+	}
+	return nil
+}
+
+// Match 检查视频是否匹配当前覆盖策略
+func (o ResolutionOverride) Match(video Result) bool {
+	for _, artist := range o.Artists {
+		if video.User.Username == artist {
+			return true
+		}
+	}
+	for _, tag := range o.Tags {
+		for _, videoTag := range video.Tags {
+			if videoTag.ID == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetResolutionPolicy 获取视频使用的分辨率选择策略,优先使用第一个匹配的覆盖策略
+func (u *User) GetResolutionPolicy(video Result) ResolutionPolicy {
+	for _, override := range u.ResolutionOverrides {
+		if override.Match(video) {
+			return override.ResolutionPolicy
+		}
+	}
+	return u.Resolution
+}
+
+// SelectVideoSource 依据分辨率选择策略选择要下载的视频源, srcs 需要按照清晰度从高到低排序
+func (u *User) SelectVideoSource(video Result, srcs []*Video) *Video {
+	if len(srcs) == 0 {
+		return nil
+	}
+	policy := u.GetResolutionPolicy(video)
+
+	// 过滤超过最高分辨率的视频源
+	candidates := srcs
+	if policy.Max != "" {
+		maxDefinition := VideoDefinitionMap[policy.Max]
+		candidates = nil
+		for _, src := range srcs {
+			if VideoDefinitionMap[src.Name] <= maxDefinition {
+				candidates = append(candidates, src)
+			}
+		}
+		if len(candidates) == 0 {
+			// 所有视频源都超过了最高分辨率,只能选择最低的分辨率
+			return srcs[len(srcs)-1]
+		}
+	}
+
+	// 依次尝试首选分辨率和备选分辨率
+	names := append([]string{policy.Preferred}, policy.Fallback...)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		for _, src := range candidates {
+			if src.Name == name {
+				return src
+			}
+		}
+	}
+
+	// 都不存在时选择允许范围内的最高分辨率
+	return candidates[0]
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSelectVideoSource tests the SelectVideoSource function
+func TestSelectVideoSource(t *testing.T) {
+	srcs := []*Video{{Name: "Source"}, {Name: "540"}, {Name: "360"}, {Name: "preview"}}
+	archive := Result{User: Artist{Username: "archive"}}
+	tagged := Result{Tags: []Tag{{ID: "mmd"}}}
+	other := Result{User: Artist{Username: "other"}}
+
+	tests := []struct {
+		name  string
+		user  *User
+		video Result
+		want  string
+	}{
+		{
+			name:  "empty policy selects highest",
+			user:  &User{},
+			video: other,
+			want:  "Source",
+		},
+		{
+			name:  "preferred missing uses fallback",
+			user:  &User{Resolution: ResolutionPolicy{Preferred: "720", Fallback: []string{"1080", "540"}}},
+			video: other,
+			want:  "540",
+		},
+		{
+			name:  "max cap selects highest allowed",
+			user:  &User{Resolution: ResolutionPolicy{Max: "720"}},
+			video: other,
+			want:  "540",
+		},
+		{
+			name:  "max cap below all sources selects lowest",
+			user:  &User{Resolution: ResolutionPolicy{Max: "240"}},
+			video: Result{},
+			want:  "preview",
+		},
+		{
+			name: "artist override",
+			user: &User{
+				Resolution:          ResolutionPolicy{Preferred: "360"},
+				ResolutionOverrides: []ResolutionOverride{{Artists: []string{"archive"}, ResolutionPolicy: ResolutionPolicy{Preferred: "Source"}}},
+			},
+			video: archive,
+			want:  "Source",
+		},
+		{
+			name: "tag override",
+			user: &User{
+				Resolution:          ResolutionPolicy{Preferred: "Source"},
+				ResolutionOverrides: []ResolutionOverride{{Tags: []string{"mmd"}, ResolutionPolicy: ResolutionPolicy{Max: "540"}}},
+			},
+			video: tagged,
+			want:  "540",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.user.SelectVideoSource(tt.video, srcs)
+			assert.Equal(t, tt.want, got.Name)
+		})
+	}
+}
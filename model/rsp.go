@@ -50,27 +50,35 @@ type Tag struct {
 
 // Result 结构体表示单个结果条目
 type Result struct {
-	ID              string      `json:"id"`
-	Slug            string      `json:"slug"`
-	Title           string      `json:"title"`
-	Body            string      `json:"body"`
-	Status          string      `json:"status"`
-	Rating          string      `json:"rating"`
-	Private         bool        `json:"private"`
-	Unlisted        bool        `json:"unlisted"`
-	Thumbnail       int         `json:"thumbnail"`
-	EmbedUrl        interface{} `json:"embedUrl"` // 可以是 nil 或具体类型
-	Liked           bool        `json:"liked"`
-	NumLikes        int         `json:"numLikes"`
-	NumViews        int         `json:"numViews"`
-	NumComments     int         `json:"numComments"`
-	File            File        `json:"file"`
-	CustomThumbnail interface{} `json:"customThumbnail"` // 可以是 nil 或具体类型
-	User            Artist      `json:"user"`
-	Tags            []Tag       `json:"tags"`
-	CreatedAt       string      `json:"createdAt"`
-	UpdatedAt       string      `json:"updatedAt"`
-	FileUrl         string      `json:"fileUrl"`
+	ID              string `json:"id"`
+	Slug            string `json:"slug"`
+	Title           string `json:"title"`
+	Body            string `json:"body"`
+	Status          string `json:"status"`
+	Rating          string `json:"rating"`
+	Private         bool   `json:"private"`
+	Unlisted        bool   `json:"unlisted"`
+	Thumbnail       int    `json:"thumbnail"`
+	EmbedUrl        string `json:"embedUrl"` // 嵌入的外部视频地址(YouTube等),普通视频为空
+	Liked           bool   `json:"liked"`
+	NumLikes        int    `json:"numLikes"`
+	NumViews        int    `json:"numViews"`
+	NumComments     int    `json:"numComments"`
+	File            File   `json:"file"`
+	CustomThumbnail *File  `json:"customThumbnail"` // 自定义缩略图,没有时为 nil
+	User            Artist `json:"user"`
+	Tags            []Tag  `json:"tags"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
+	FileUrl         string `json:"fileUrl"`
+}
+
+// ExpectSize 获取指定分辨率视频文件的预期大小,只有源文件的大小是已知的,未知时返回0
+func (r Result) ExpectSize(name string) int64 {
+	if name != "Source" {
+		return 0
+	}
+	return r.File.Size
 }
 
 // PageDataRoot 结构体表示整个 JSON 数据
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/nfo"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// nfoPath 获取视频nfo文件的路径
+func nfoPath(videoPath string) string {
+	return strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".nfo"
+}
+
+// saveNfo 在视频旁边生成nfo文件
+func saveNfo(videoPath string, video model.Result) {
+	if err := nfo.Write(nfoPath(videoPath), video); err != nil {
+		log.Println("生成nfo文件失败:", video.Title, err)
+		return
+	}
+	log.Println("nfo文件生成完成:", nfoPath(videoPath))
+}
+
+// backfillNfo 为数据库中记录的所有已下载视频生成缺失的nfo文件
+func backfillNfo() {
+	start := time.Now()
+	var count int
+	err := rangeDownloadedVideo(func(filePath string, videoPath string, data model.VideoData) {
+		if files.CheckFileExists(nfoPath(videoPath)) {
+			return
+		}
+		saveNfo(videoPath, *data.Video)
+		count++
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+	log.Println("nfo文件生成任务完成, 一共生成", count, "个nfo文件")
+	log.Println("本次任务耗时:", time.Since(start))
+}
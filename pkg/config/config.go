@@ -69,12 +69,47 @@ func init() {
 		}
 	}
 
+	if consts.FlagConf.Workers > 0 {
+		c.DownloadWorkers = consts.FlagConf.Workers
+	}
+	if consts.FlagConf.Segments > 0 {
+		c.DownloadSegments = consts.FlagConf.Segments
+	}
+	if c.MaxDownloadRetry <= 0 {
+		c.MaxDownloadRetry = consts.DEFAULT_MAX_DOWNLOAD_RETRY
+	}
+	if c.ProgressInterval == 0 {
+		c.ProgressInterval = consts.DEFAULT_PROGRESS_INTERVAL
+	}
+	if c.DiskReserve <= 0 {
+		c.DiskReserve = consts.DEFAULT_DISK_RESERVE
+	}
+	if consts.FlagConf.BandwidthLimit > 0 {
+		c.BandwidthLimit = consts.FlagConf.BandwidthLimit
+	}
+
 	if c.Username == "" || c.Password == "" {
 		log.Fatalln("用户名或密码为空, 请使用 -u 和 -p 指定用户名密码,或者配置好", configFileName, "配置文件")
 	}
 	if c.Hot && c.Subscribe {
 		log.Fatalln("订阅模式和热门模式不能同时开启")
 	}
+	if consts.FlagConf.Resolution != "" {
+		c.Resolution.Preferred = consts.FlagConf.Resolution
+	}
+	if err := c.Resolution.Check(); err != nil {
+		log.Fatalln(err, "可选的分辨率为:", "Source, 1080, 720, 540, 480, 360, 240, 144, 108, 96, preview")
+	}
+	for _, override := range c.ResolutionOverrides {
+		if err := override.Check(); err != nil {
+			log.Fatalln(err, "可选的分辨率为:", "Source, 1080, 720, 540, 480, 360, 240, 144, 108, 96, preview")
+		}
+	}
+	for _, schedule := range c.BandwidthSchedules {
+		if err := schedule.Check(); err != nil {
+			log.Fatalln(err, schedule.Start, "-", schedule.End, ", 时间格式为 15:04, 限速值不能小于0")
+		}
+	}
 
 	Config = c
 }
@@ -0,0 +1,6 @@
+package disk
+
+// Free 获取指定路径所在文件系统中当前用户可用的剩余空间(字节)
+func Free(path string) (uint64, error) {
+	return free(path)
+}
@@ -80,11 +80,17 @@ func CheckFileExists(filename string) bool {
 
 // CheckVideoFileExist 检查指定目录下的视频文件是否存在
 func CheckVideoFileExist(baseName string, dirPath string) string {
+	fileName, _ := FindVideoFile(baseName, dirPath)
+	return fileName
+}
+
+// FindVideoFile 查找指定目录下的视频文件,返回文件名和对应的分辨率
+func FindVideoFile(baseName string, dirPath string) (string, string) {
 	for k, _ := range model.VideoDefinitionMap {
 		tempName := baseName + " [" + k + "].mp4"
 		if CheckFileExists(dirPath + string(os.PathSeparator) + tempName) {
-			return tempName
+			return tempName, k
 		}
 	}
-	return ""
+	return "", ""
 }
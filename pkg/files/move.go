@@ -0,0 +1,57 @@
+package files
+
+import (
+	"io"
+	"os"
+)
+
+// MoveFile 移动文件,目标目录在其他文件系统上时复制文件后再删除源文件
+func MoveFile(src string, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	return moveByCopy(src, dst)
+}
+
+// moveByCopy 复制文件到目标位置并同步到磁盘,完成后删除源文件
+// 先写入临时文件再重命名,复制中断时不会留下不完整的目标文件
+func moveByCopy(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+
+	in.Close()
+	return os.Remove(src)
+}
@@ -0,0 +1,36 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMoveByCopy tests the moveByCopy function
+func TestMoveByCopy(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.mp4")
+	dst := filepath.Join(dir, "dst.mp4")
+	assert.NoError(t, os.WriteFile(src, []byte("video data"), 0644))
+
+	assert.NoError(t, moveByCopy(src, dst))
+	assert.False(t, CheckFileExists(src))
+	assert.False(t, CheckFileExists(dst+".tmp"))
+	data, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "video data", string(data))
+}
+
+// TestMoveFile tests the MoveFile function
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.mp4")
+	dst := filepath.Join(dir, "dst.mp4")
+	assert.NoError(t, os.WriteFile(src, []byte("video data"), 0644))
+
+	assert.NoError(t, MoveFile(src, dst))
+	assert.False(t, CheckFileExists(src))
+	assert.True(t, CheckFileExists(dst))
+}
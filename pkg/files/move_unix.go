@@ -0,0 +1,13 @@
+//go:build !windows
+
+package files
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice 检查错误是否是跨文件系统移动文件导致的
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,14 @@
+package files
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorNotSameDevice windows 跨磁盘移动文件时返回的错误码 ERROR_NOT_SAME_DEVICE
+const errorNotSameDevice syscall.Errno = 17
+
+// isCrossDevice 检查错误是否是跨文件系统移动文件导致的
+func isCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice) || errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,29 @@
+package files
+
+import (
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/mp4"
+	"fmt"
+	"os"
+)
+
+// VerifyVideoFile 校验下载完成的视频文件
+// expectSize 大于0时会额外校验文件大小是否一致
+func VerifyVideoFile(filePath string, expectSize int64) error {
+	if err := VerifyFileSize(filePath, expectSize); err != nil {
+		return err
+	}
+	return mp4.Check(filePath)
+}
+
+// VerifyFileSize 校验文件大小,expectSize 小于等于0时只检查文件是否存在
+func VerifyFileSize(filePath string, expectSize int64) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if expectSize > 0 && info.Size() != expectSize {
+		return fmt.Errorf("%w: 期望 %d 字节, 实际 %d 字节", model.ErrVideoSizeMismatch, expectSize, info.Size())
+	}
+	return nil
+}
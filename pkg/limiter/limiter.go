@@ -0,0 +1,96 @@
+package limiter
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	rateRefreshGap = time.Second // 重新获取限速值的间隔
+)
+
+// Bucket 令牌桶限速器,多个下载连接共享同一个令牌桶时限制的是总速度
+type Bucket struct {
+	lock        sync.Mutex
+	rateFunc    func() int64 // 获取当前每秒允许的字节数,小于等于0表示不限速
+	rate        int64        // 当前每秒允许的字节数
+	tokens      float64      // 当前剩余令牌
+	last        time.Time    // 上次补充令牌的时间
+	lastRefresh time.Time    // 上次获取限速值的时间
+}
+
+// NewBucket 创建令牌桶,限速值会定时通过 rateFunc 重新获取,以支持按时间段调整限速
+func NewBucket(rateFunc func() int64) *Bucket {
+	return &Bucket{rateFunc: rateFunc}
+}
+
+// refresh 补充令牌并按需重新获取限速值,调用方需要持有锁
+func (b *Bucket) refresh(now time.Time) {
+	if now.Sub(b.lastRefresh) >= rateRefreshGap {
+		rate := b.rateFunc()
+		if rate != b.rate {
+			// 限速值变化后重新开始计算令牌
+			b.rate = rate
+			b.tokens = 0
+			b.last = now
+		}
+		b.lastRefresh = now
+	}
+	if b.rate <= 0 {
+		return
+	}
+	b.tokens += now.Sub(b.last).Seconds() * float64(b.rate)
+	// 最多积累1秒的令牌
+	if b.tokens > float64(b.rate) {
+		b.tokens = float64(b.rate)
+	}
+	b.last = now
+}
+
+// Wait 等待直到可以传输 n 个字节
+func (b *Bucket) Wait(n int) {
+	remain := float64(n)
+	for remain > 0 {
+		b.lock.Lock()
+		b.refresh(time.Now())
+		if b.rate <= 0 {
+			b.lock.Unlock()
+			return
+		}
+
+		take := remain
+		if take > float64(b.rate) {
+			take = float64(b.rate)
+		}
+		if b.tokens >= take {
+			b.tokens -= take
+			remain -= take
+			b.lock.Unlock()
+			continue
+		}
+		wait := time.Duration((take - b.tokens) / float64(b.rate) * float64(time.Second))
+		b.lock.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// Reader 返回受令牌桶限速的 io.Reader
+func (b *Bucket) Reader(r io.Reader) io.Reader {
+	return &reader{r: r, bucket: b}
+}
+
+// reader 受限速的 io.Reader
+type reader struct {
+	r      io.Reader
+	bucket *Bucket
+}
+
+// Read 实现io.Reader接口
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.bucket.Wait(n)
+	}
+	return n, err
+}
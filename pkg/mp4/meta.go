@@ -0,0 +1,327 @@
+package mp4
+
+import (
+	"IwaraDownload/model"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// iTunes 元数据的 ilst 子box类型
+const (
+	itemTitle       = "\xa9nam" // 标题
+	itemArtist      = "\xa9ART" // 作者
+	itemComment     = "\xa9cmt" // 注释
+	itemGenre       = "\xa9gen" // 类型
+	itemDate        = "\xa9day" // 创建日期
+	itemDescription = "desc"    // 简介
+)
+
+// Metadata 写入视频文件的 iTunes 风格元数据
+type Metadata struct {
+	Title       string // 标题
+	Artist      string // 作者
+	Comment     string // 注释,包含视频地址和视频ID
+	Genre       string // 类型,使用视频标签
+	Date        string // 创建日期
+	Description string // 简介
+}
+
+// NewMetadata 使用视频数据生成元数据
+func NewMetadata(video model.Result) Metadata {
+	tags := make([]string, 0, len(video.Tags))
+	for _, tag := range video.Tags {
+		tags = append(tags, tag.ID)
+	}
+	return Metadata{
+		Title:       video.Title,
+		Artist:      video.User.Username,
+		Comment:     fmt.Sprintf(model.VideoPageUrl+" ID: %s", video.ID, video.ID),
+		Genre:       strings.Join(tags, ", "),
+		Date:        video.CreatedAt,
+		Description: video.Body,
+	}
+}
+
+// items 元数据对应的 ilst 子box
+func (m Metadata) items() [][2]string {
+	return [][2]string{
+		{itemTitle, m.Title},
+		{itemArtist, m.Artist},
+		{itemComment, m.Comment},
+		{itemGenre, m.Genre},
+		{itemDate, m.Date},
+		{itemDescription, m.Description},
+	}
+}
+
+// newMetaBox 生成包含 ilst 的 meta box
+func newMetaBox(m Metadata) []byte {
+	var ilst []byte
+	for _, item := range m.items() {
+		if item[1] == "" {
+			continue
+		}
+		// data box: 4字节版本和类型(1表示UTF-8文本) + 4字节语言 + 内容
+		data := make([]byte, 8, 8+len(item[1]))
+		binary.BigEndian.PutUint32(data, 1)
+		data = append(data, item[1]...)
+		ilst = append(ilst, newBox(item[0], newBox("data", data))...)
+	}
+
+	// hdlr box: 版本和标志 + pre_defined + 处理类型 mdir + 保留字段 + 空名称
+	hdlr := make([]byte, 0, 25)
+	hdlr = append(hdlr, 0, 0, 0, 0, 0, 0, 0, 0)
+	hdlr = append(hdlr, "mdirappl"...)
+	hdlr = append(hdlr, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+
+	// meta 是 full box,内容前面有4字节的版本和标志
+	payload := []byte{0, 0, 0, 0}
+	payload = append(payload, newBox("hdlr", hdlr)...)
+	payload = append(payload, newBox("ilst", ilst)...)
+	return newBox("meta", payload)
+}
+
+// buildMoov 生成替换了 udta/meta 的新 moov box
+func buildMoov(moov []byte, headerSize int64, meta []byte) ([]byte, error) {
+	r := bytes.NewReader(moov)
+	children, err := ReadBoxes(r, headerSize, int64(len(moov)))
+	if err != nil {
+		return nil, err
+	}
+
+	var payload, udta []byte
+	for _, child := range children {
+		raw := moov[child.Offset : child.Offset+child.Size]
+		if child.Type != "udta" {
+			payload = append(payload, raw...)
+			continue
+		}
+		// 保留 udta 中除了 meta 以外的其他数据
+		subs, err := ReadBoxes(r, child.Offset+child.HeaderSize, child.Offset+child.Size)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subs {
+			if sub.Type != "meta" {
+				udta = append(udta, moov[sub.Offset:sub.Offset+sub.Size]...)
+			}
+		}
+	}
+	udta = append(udta, meta...)
+	payload = append(payload, newBox("udta", udta)...)
+	return newBox("moov", payload), nil
+}
+
+// shiftChunkOffsets 修正 moov 中所有指向 threshold 之后数据的 chunk 偏移
+func shiftChunkOffsets(moov []byte, start int64, end int64, threshold int64, delta int64) error {
+	boxes, err := ReadBoxes(bytes.NewReader(moov), start, end)
+	if err != nil {
+		return err
+	}
+	for _, box := range boxes {
+		payload := box.Offset + box.HeaderSize
+		boxEnd := box.Offset + box.Size
+		switch box.Type {
+		case "moov", "trak", "mdia", "minf", "stbl":
+			if err := shiftChunkOffsets(moov, payload, boxEnd, threshold, delta); err != nil {
+				return err
+			}
+		case "stco", "co64":
+			entrySize := int64(4)
+			if box.Type == "co64" {
+				entrySize = 8
+			}
+			if boxEnd-payload < 8 {
+				return model.ErrVideoInvalid
+			}
+			count := int64(binary.BigEndian.Uint32(moov[payload+4:]))
+			if payload+8+count*entrySize > boxEnd {
+				return model.ErrVideoInvalid
+			}
+			for i := int64(0); i < count; i++ {
+				pos := payload + 8 + i*entrySize
+				if entrySize == 4 {
+					offset := int64(binary.BigEndian.Uint32(moov[pos:]))
+					if offset < threshold {
+						continue
+					}
+					if offset+delta > math.MaxUint32 {
+						return model.ErrVideoInvalid
+					}
+					binary.BigEndian.PutUint32(moov[pos:], uint32(offset+delta))
+				} else {
+					offset := int64(binary.BigEndian.Uint64(moov[pos:]))
+					if offset < threshold {
+						continue
+					}
+					binary.BigEndian.PutUint64(moov[pos:], uint64(offset+delta))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WriteMetadata 将元数据写入视频文件的 moov/udta/meta/ilst 中,已有的元数据会被替换
+// moov 位于文件末尾时直接在原文件上修改,否则需要重写整个文件
+func WriteMetadata(filePath string, m Metadata) error {
+	file, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	size := info.Size()
+
+	boxes, err := ReadBoxes(file, 0, size)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	var moov *Box
+	for i := range boxes {
+		if boxes[i].Type == "moov" {
+			moov = &boxes[i]
+			break
+		}
+	}
+	if moov == nil {
+		file.Close()
+		return model.ErrVideoInvalid
+	}
+
+	moovData := make([]byte, moov.Size)
+	if _, err := file.ReadAt(moovData, moov.Offset); err != nil {
+		file.Close()
+		return err
+	}
+	newMoov, err := buildMoov(moovData, moov.HeaderSize, newMetaBox(m))
+	if err != nil {
+		file.Close()
+		return err
+	}
+	moovEnd := moov.Offset + moov.Size
+	delta := int64(len(newMoov)) - moov.Size
+	if delta != 0 {
+		// moov 后面的数据位置发生了变化,需要修正指向这些数据的偏移
+		if err := shiftChunkOffsets(newMoov, 8, int64(len(newMoov)), moovEnd, delta); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if moovEnd == size {
+		// moov 在文件末尾,直接覆盖写入
+		defer file.Close()
+		if _, err := file.WriteAt(newMoov, moov.Offset); err != nil {
+			return err
+		}
+		return file.Truncate(moov.Offset + int64(len(newMoov)))
+	}
+
+	// moov 在文件中间,写入临时文件后替换原文件
+	tempPath := filePath + ".tmp"
+	err = rewriteFile(file, tempPath, size, moov.Offset, moovEnd, newMoov)
+	file.Close()
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	return os.Rename(tempPath, filePath)
+}
+
+// rewriteFile 将原文件中的 [moovStart, moovEnd) 替换为新的 moov 写入到临时文件
+func rewriteFile(file *os.File, tempPath string, size int64, moovStart int64, moovEnd int64, newMoov []byte) error {
+	temp, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+	defer temp.Close()
+	if _, err := io.Copy(temp, io.NewSectionReader(file, 0, moovStart)); err != nil {
+		return err
+	}
+	if _, err := temp.Write(newMoov); err != nil {
+		return err
+	}
+	if _, err := io.Copy(temp, io.NewSectionReader(file, moovEnd, size-moovEnd)); err != nil {
+		return err
+	}
+	return temp.Close()
+}
+
+// ReadMetadata 读取视频文件中的 iTunes 风格元数据
+func ReadMetadata(filePath string) (Metadata, error) {
+	var m Metadata
+	file, err := os.Open(filePath)
+	if err != nil {
+		return m, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return m, err
+	}
+
+	// 依次查找 moov/udta/meta/ilst
+	start, end := int64(0), info.Size()
+	for _, boxType := range []string{"moov", "udta", "meta", "ilst"} {
+		boxes, err := ReadBoxes(file, start, end)
+		if err != nil {
+			return m, err
+		}
+		found := false
+		for _, box := range boxes {
+			if box.Type == boxType {
+				start, end = box.Offset+box.HeaderSize, box.Offset+box.Size
+				if boxType == "meta" {
+					// 跳过 full box 的版本和标志
+					start += 4
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return m, nil
+		}
+	}
+
+	items, err := ReadBoxes(file, start, end)
+	if err != nil {
+		return m, err
+	}
+	for _, item := range items {
+		// data box 头部8字节 + 版本和类型4字节 + 语言4字节
+		if item.Size-item.HeaderSize < 16 {
+			continue
+		}
+		data := make([]byte, item.Size-item.HeaderSize-16)
+		if _, err := file.ReadAt(data, item.Offset+item.HeaderSize+16); err != nil {
+			return m, err
+		}
+		value := string(data)
+		switch item.Type {
+		case itemTitle:
+			m.Title = value
+		case itemArtist:
+			m.Artist = value
+		case itemComment:
+			m.Comment = value
+		case itemGenre:
+			m.Genre = value
+		case itemDate:
+			m.Date = value
+		case itemDescription:
+			m.Description = value
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,90 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestVideo 生成一个包含单个 chunk 偏移的视频文件, chunk 指向 mdat 中的 marker
+func newTestVideo(moovFirst bool) []byte {
+	ftyp := newBox("ftyp", []byte("isom0000"))
+	marker := []byte("MARK")
+	mdat := newBox("mdat", append(make([]byte, 16), marker...))
+
+	newMoov := func(chunkOffset uint32) []byte {
+		stco := make([]byte, 12)
+		binary.BigEndian.PutUint32(stco[4:], 1)
+		binary.BigEndian.PutUint32(stco[8:], chunkOffset)
+		stbl := newBox("stbl", newBox("stco", stco))
+		trak := newBox("trak", newBox("mdia", newBox("minf", stbl)))
+		udta := newBox("udta", newBox("cprt", []byte("keep")))
+		return newBox("moov", append(trak, udta...))
+	}
+
+	// marker 在 mdat 中的偏移为 头部8字节 + 16字节填充
+	moovSize := len(newMoov(0))
+	if moovFirst {
+		offset := uint32(len(ftyp) + moovSize + 8 + 16)
+		return bytes.Join([][]byte{ftyp, newMoov(offset), mdat}, nil)
+	}
+	offset := uint32(len(ftyp) + 8 + 16)
+	return bytes.Join([][]byte{ftyp, mdat, newMoov(offset)}, nil)
+}
+
+// readChunkMarker 读取 stco 中第一个 chunk 偏移指向的4个字节
+func readChunkMarker(t *testing.T, data []byte) string {
+	boxes, err := ReadBoxes(bytes.NewReader(data), 0, int64(len(data)))
+	assert.NoError(t, err)
+	for _, box := range boxes {
+		if box.Type != "moov" {
+			continue
+		}
+		index := bytes.Index(data[box.Offset:box.Offset+box.Size], []byte("stco"))
+		pos := box.Offset + int64(index) + 4 + 8
+		offset := binary.BigEndian.Uint32(data[pos:])
+		return string(data[offset : offset+4])
+	}
+	return ""
+}
+
+// TestWriteMetadata tests the WriteMetadata and ReadMetadata functions
+func TestWriteMetadata(t *testing.T) {
+	meta := Metadata{
+		Title:   "标题",
+		Artist:  "artist",
+		Comment: "https://www.iwara.tv/video/abc ID: abc",
+		Genre:   "mmd, dance",
+		Date:    "2024-03-05T12:00:00.000Z",
+	}
+
+	for _, moovFirst := range []bool{true, false} {
+		name := "moov at end"
+		if moovFirst {
+			name = "moov before mdat"
+		}
+		t.Run(name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "test.mp4")
+			assert.NoError(t, os.WriteFile(filePath, newTestVideo(moovFirst), 0666))
+
+			// 写入两次,第二次应该替换第一次写入的元数据
+			assert.NoError(t, WriteMetadata(filePath, Metadata{Title: "old"}))
+			assert.NoError(t, WriteMetadata(filePath, meta))
+			assert.NoError(t, Check(filePath))
+
+			got, err := ReadMetadata(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, meta, got)
+
+			data, err := os.ReadFile(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, "MARK", readChunkMarker(t, data))
+			assert.Equal(t, 1, bytes.Count(data, []byte("ilst")))
+			assert.Contains(t, string(data), "cprtkeep")
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package mp4
+
+import (
+	"IwaraDownload/model"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Box 结构体表示MP4文件中的一个box
+type Box struct {
+	Type       string // box类型
+	Offset     int64  // box在文件中的起始位置
+	Size       int64  // box总大小(包含头部)
+	HeaderSize int64  // box头部大小
+}
+
+// newBox 生成一个指定类型和内容的box
+func newBox(boxType string, payload []byte) []byte {
+	data := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(data, uint32(8+len(payload)))
+	copy(data[4:], boxType)
+	return append(data, payload...)
+}
+
+// ReadBoxes 读取 [offset, end) 范围内同一层级的所有box
+func ReadBoxes(r io.ReaderAt, offset int64, end int64) ([]Box, error) {
+	var boxes []Box
+	header := make([]byte, 16)
+	for offset < end {
+		if end-offset < 8 {
+			return boxes, model.ErrVideoTruncated
+		}
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return boxes, model.ErrVideoTruncated
+		}
+		box := Box{
+			Type:       string(header[4:8]),
+			Offset:     offset,
+			Size:       int64(binary.BigEndian.Uint32(header[:4])),
+			HeaderSize: 8,
+		}
+		switch box.Size {
+		case 0:
+			// 大小为0表示box一直延续到文件末尾
+			box.Size = end - offset
+		case 1:
+			// 大小为1表示使用64位的扩展大小
+			if end-offset < 16 {
+				return boxes, model.ErrVideoTruncated
+			}
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return boxes, model.ErrVideoTruncated
+			}
+			box.Size = int64(binary.BigEndian.Uint64(header[8:16]))
+			box.HeaderSize = 16
+		}
+		if box.Size < box.HeaderSize {
+			return boxes, model.ErrVideoInvalid
+		}
+		if offset+box.Size > end {
+			return boxes, model.ErrVideoTruncated
+		}
+		boxes = append(boxes, box)
+		offset += box.Size
+	}
+	return boxes, nil
+}
+
+// Check 检查MP4文件的顶层box结构是否完整
+func Check(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	boxes, err := ReadBoxes(file, 0, info.Size())
+	if err != nil {
+		return err
+	}
+
+	// 可以播放的视频文件至少需要包含 moov 和 mdat
+	var hasMoov, hasMdat bool
+	for _, box := range boxes {
+		switch box.Type {
+		case "moov":
+			hasMoov = true
+		case "mdat":
+			hasMdat = true
+		}
+	}
+	if !hasMoov || !hasMdat {
+		return model.ErrVideoInvalid
+	}
+	return nil
+}
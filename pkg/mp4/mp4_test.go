@@ -0,0 +1,59 @@
+package mp4
+
+import (
+	"IwaraDownload/model"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheck tests the Check function
+func TestCheck(t *testing.T) {
+	var full []byte
+	full = append(full, newBox("ftyp", []byte("isom0000"))...)
+	full = append(full, newBox("moov", make([]byte, 32))...)
+	full = append(full, newBox("mdat", make([]byte, 128))...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name:    "complete file",
+			data:    full,
+			wantErr: nil,
+		},
+		{
+			name:    "truncated file",
+			data:    full[:len(full)-10],
+			wantErr: model.ErrVideoTruncated,
+		},
+		{
+			name:    "missing moov",
+			data:    append(newBox("ftyp", []byte("isom0000")), newBox("mdat", make([]byte, 16))...),
+			wantErr: model.ErrVideoInvalid,
+		},
+		{
+			name:    "invalid box size",
+			data:    append(newBox("ftyp", []byte("isom0000")), 0, 0, 0, 4, 'm', 'o', 'o', 'v'),
+			wantErr: model.ErrVideoInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "test.mp4")
+			assert.NoError(t, os.WriteFile(filePath, tt.data, 0666))
+
+			err := Check(filePath)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.wantErr, err)
+			}
+		})
+	}
+}
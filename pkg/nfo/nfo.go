@@ -0,0 +1,90 @@
+package nfo
+
+import (
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// UniqueID 视频在来源网站的唯一ID
+type UniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// Rating 评分信息
+type Rating struct {
+	Name    string  `xml:"name,attr"`
+	Max     int     `xml:"max,attr"`
+	Default bool    `xml:"default,attr"`
+	Value   float64 `xml:"value"`
+	Votes   int     `xml:"votes"`
+}
+
+// Movie Kodi/Jellyfin 使用的 movie nfo 格式
+type Movie struct {
+	XMLName   xml.Name   `xml:"movie"`
+	Title     string     `xml:"title"`
+	Plot      string     `xml:"plot"`
+	Studio    string     `xml:"studio"`
+	Director  string     `xml:"director"`
+	Genres    []string   `xml:"genre"`
+	Premiered string     `xml:"premiered,omitempty"`
+	Year      int        `xml:"year,omitempty"`
+	Runtime   int        `xml:"runtime,omitempty"` // 时长(分钟)
+	Ratings   []Rating   `xml:"ratings>rating"`
+	UniqueIDs []UniqueID `xml:"uniqueid"`
+	Source    string     `xml:"source"`
+}
+
+// NewMovie 使用视频数据生成nfo数据
+func NewMovie(video model.Result) Movie {
+	movie := Movie{
+		Title:    video.Title,
+		Plot:     video.Body,
+		Studio:   video.User.Name,
+		Director: video.User.Username,
+		Runtime:  video.File.Duration / 60,
+		UniqueIDs: []UniqueID{
+			{Type: "iwara", Default: true, Value: video.ID},
+		},
+		Source: fmt.Sprintf(model.VideoPageUrl, video.ID),
+	}
+	for _, tag := range video.Tags {
+		movie.Genres = append(movie.Genres, tag.ID)
+	}
+	if createTime, err := time.Parse(time.RFC3339, video.CreatedAt); err == nil {
+		createTime = createTime.Local()
+		movie.Premiered = createTime.Format(time.DateOnly)
+		movie.Year = createTime.Year()
+	}
+
+	// 评分使用点赞率(点赞数/播放数),满分100
+	rating := Rating{Name: "iwara", Max: 100, Default: true, Votes: video.NumLikes}
+	if video.NumViews > 0 {
+		rating.Value = float64(int(float64(video.NumLikes)/float64(video.NumViews)*1000)) / 10
+	}
+	movie.Ratings = []Rating{rating}
+	return movie
+}
+
+// Marshal 生成nfo文件内容
+func Marshal(video model.Result) ([]byte, error) {
+	data, err := xml.MarshalIndent(NewMovie(video), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Write 生成nfo文件并写入到指定路径
+func Write(filePath string, video model.Result) error {
+	data, err := Marshal(video)
+	if err != nil {
+		return err
+	}
+	return files.WriteFile(filePath, data)
+}
@@ -0,0 +1,40 @@
+package nfo
+
+import (
+	"IwaraDownload/model"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarshal tests the Marshal function
+func TestMarshal(t *testing.T) {
+	video := model.Result{
+		ID:        "abc123",
+		Title:     "Title <&>",
+		Body:      "Description",
+		NumLikes:  50,
+		NumViews:  1000,
+		File:      model.File{Duration: 185},
+		User:      model.Artist{Name: "Nickname", Username: "username"},
+		Tags:      []model.Tag{{ID: "mmd"}, {ID: "dance"}},
+		CreatedAt: "2024-03-05T12:00:00.000Z",
+	}
+
+	data, err := Marshal(video)
+	assert.NoError(t, err)
+
+	var movie Movie
+	assert.NoError(t, xml.Unmarshal(data, &movie))
+	assert.Equal(t, "Title <&>", movie.Title)
+	assert.Equal(t, "Description", movie.Plot)
+	assert.Equal(t, "Nickname", movie.Studio)
+	assert.Equal(t, []string{"mmd", "dance"}, movie.Genres)
+	assert.Equal(t, 2024, movie.Year)
+	assert.Equal(t, 3, movie.Runtime)
+	assert.Equal(t, 5.0, movie.Ratings[0].Value)
+	assert.Equal(t, 50, movie.Ratings[0].Votes)
+	assert.Equal(t, "abc123", movie.UniqueIDs[0].Value)
+	assert.Equal(t, "https://www.iwara.tv/video/abc123", movie.Source)
+}
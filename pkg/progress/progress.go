@@ -0,0 +1,235 @@
+package progress
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	subscriberBuffer = 64 // 订阅者通道的缓冲大小,订阅者处理不及时的时候丢弃进度
+)
+
+// Status 下载进度
+type Status struct {
+	Name      string        // 下载的文件名
+	Done      int64         // 已下载字节数
+	Total     int64         // 总字节数,未知时为0
+	Speed     float64       // 最近一个统计周期的下载速度(字节/秒)
+	ETA       time.Duration // 预计剩余时间,未知时为-1
+	StartedAt time.Time     // 开始下载的时间
+	UpdatedAt time.Time     // 进度更新时间
+	Finished  bool          // 是否已经结束下载
+}
+
+// Percent 下载百分比,总大小未知时返回-1
+func (s Status) Percent() float64 {
+	if s.Total <= 0 {
+		return -1
+	}
+	return float64(s.Done) / float64(s.Total) * 100
+}
+
+// String 格式化下载进度
+func (s Status) String() string {
+	percent := "未知"
+	if p := s.Percent(); p >= 0 {
+		percent = fmt.Sprintf("%.1f%%", p)
+	}
+	eta := "未知"
+	if s.ETA >= 0 {
+		eta = s.ETA.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%s 进度: %s %s/%s 速度: %s/s 剩余时间: %s", s.Name, percent, FormatSize(s.Done), FormatSize(s.Total), FormatSize(int64(s.Speed)), eta)
+}
+
+// FormatSize 格式化字节数
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+var (
+	registryLock sync.Mutex
+	trackers     = make(map[*Tracker]bool)
+	subscribers  = make(map[chan Status]bool)
+)
+
+// List 获取所有正在下载的任务进度
+func List() []Status {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	list := make([]Status, 0, len(trackers))
+	for t := range trackers {
+		list = append(list, t.Status())
+	}
+	return list
+}
+
+// Subscribe 订阅下载进度更新,返回的函数用于取消订阅
+func Subscribe() (<-chan Status, func()) {
+	ch := make(chan Status, subscriberBuffer)
+	registryLock.Lock()
+	subscribers[ch] = true
+	registryLock.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			registryLock.Lock()
+			delete(subscribers, ch)
+			registryLock.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish 发送进度给所有订阅者
+func publish(status Status) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for ch := range subscribers {
+		select {
+		case ch <- status:
+		default:
+		}
+	}
+}
+
+// Tracker 单个下载任务的进度统计
+type Tracker struct {
+	lock     sync.Mutex
+	status   Status
+	lastDone int64     // 上个统计周期结束时的已下载字节数
+	lastTime time.Time // 上个统计周期结束的时间
+	stop     chan struct{}
+}
+
+// Start 开始统计下载进度, interval 大于0时按照该间隔输出进度日志并通知订阅者
+func Start(name string, total int64, interval time.Duration) *Tracker {
+	now := time.Now()
+	t := &Tracker{
+		status: Status{
+			Name:      name,
+			Total:     total,
+			ETA:       -1,
+			StartedAt: now,
+			UpdatedAt: now,
+		},
+		lastTime: now,
+		stop:     make(chan struct{}),
+	}
+	registryLock.Lock()
+	trackers[t] = true
+	registryLock.Unlock()
+
+	if interval > 0 {
+		go t.report(interval)
+	}
+	return t
+}
+
+// report 定时输出进度
+func (t *Tracker) report(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			status := t.update()
+			log.Println("下载中:", status)
+			publish(status)
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// update 计算最近一个统计周期的速度和剩余时间
+func (t *Tracker) update() Status {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	if elapsed := now.Sub(t.lastTime).Seconds(); elapsed > 0 {
+		t.status.Speed = float64(t.status.Done-t.lastDone) / elapsed
+	}
+	t.status.ETA = -1
+	if t.status.Total > 0 && t.status.Speed > 0 {
+		t.status.ETA = time.Duration(float64(t.status.Total-t.status.Done) / t.status.Speed * float64(time.Second))
+	}
+	t.status.UpdatedAt = now
+	t.lastDone = t.status.Done
+	t.lastTime = now
+	return t.status
+}
+
+// Status 获取当前进度
+func (t *Tracker) Status() Status {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.status
+}
+
+// SetTotal 设置总字节数
+func (t *Tracker) SetTotal(total int64) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	t.status.Total = total
+	t.lock.Unlock()
+}
+
+// SetDone 设置已下载字节数,用于续传或重新下载时校正进度
+func (t *Tracker) SetDone(done int64) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	t.status.Done = done
+	t.lastDone = done
+	t.lock.Unlock()
+}
+
+// Add 增加已下载字节数
+func (t *Tracker) Add(n int64) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	t.status.Done += n
+	t.lock.Unlock()
+}
+
+// Write 实现io.Writer接口,写入的字节数计入下载进度
+func (t *Tracker) Write(p []byte) (int, error) {
+	t.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Finish 结束统计下载进度
+func (t *Tracker) Finish() {
+	if t == nil {
+		return
+	}
+	close(t.stop)
+	registryLock.Lock()
+	delete(trackers, t)
+	registryLock.Unlock()
+
+	t.lock.Lock()
+	t.status.Finished = true
+	t.status.UpdatedAt = time.Now()
+	status := t.status
+	t.lock.Unlock()
+	publish(status)
+}
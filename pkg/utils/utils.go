@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 )
 
@@ -33,3 +34,47 @@ func GenXVersion(apiUrl string) (string, error) {
 	h.Write([]byte(fmt.Sprintf("%s_%s_5nFp9kmbNnHdAFhaqMvt", iwaraFilename, iwaraExpires)))
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
+
+// ParseVideoID 从视频地址或视频ID中解析出视频ID,无法解析时返回空字符串
+// 支持 https://www.iwara.tv/video/{id}/{slug} 形式的地址以及直接填写的视频ID
+func ParseVideoID(s string) string {
+	return parsePathValue(s, "video", "videos")
+}
+
+// ParseUsername 从用户主页地址或用户名中解析出用户名,无法解析时返回空字符串
+// 支持 https://www.iwara.tv/profile/{username}/videos 形式的地址以及直接填写的用户名
+func ParseUsername(s string) string {
+	return parsePathValue(s, "profile")
+}
+
+// ParsePlaylistID 从播放列表地址或播放列表ID中解析出播放列表ID,无法解析时返回空字符串
+// 支持 https://www.iwara.tv/playlist/{id}/{slug} 形式的地址以及直接填写的播放列表ID
+func ParsePlaylistID(s string) string {
+	return parsePathValue(s, "playlist", "playlists")
+}
+
+// parsePathValue 解析地址中紧跟在指定路径名后面的值,不是地址时直接返回原值
+func parsePathValue(s string, keys ...string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	if !strings.Contains(s, "/") {
+		return s
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if slices.Contains(keys, parts[i]) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseVideoID tests the ParseVideoID function
+func TestParseVideoID(t *testing.T) {
+	assert.Equal(t, "abc123", ParseVideoID("abc123"))
+	assert.Equal(t, "abc123", ParseVideoID("  abc123\n"))
+	assert.Equal(t, "abc123", ParseVideoID("https://www.iwara.tv/video/abc123/some-title"))
+	assert.Equal(t, "abc123", ParseVideoID("https://www.iwara.tv/video/abc123"))
+	assert.Equal(t, "abc123", ParseVideoID("www.iwara.tv/video/abc123/"))
+	assert.Equal(t, "abc123", ParseVideoID("https://api.iwara.tv/videos/abc123?x=1"))
+	assert.Equal(t, "", ParseVideoID("https://www.iwara.tv/profile/someone"))
+	assert.Equal(t, "", ParseVideoID(""))
+}
+
+// TestParseUsername tests the ParseUsername function
+func TestParseUsername(t *testing.T) {
+	assert.Equal(t, "someone", ParseUsername("someone"))
+	assert.Equal(t, "someone", ParseUsername("https://www.iwara.tv/profile/someone"))
+	assert.Equal(t, "someone", ParseUsername("https://www.iwara.tv/profile/someone/videos"))
+	assert.Equal(t, "someone", ParseUsername("www.iwara.tv/profile/someone/"))
+	assert.Equal(t, "", ParseUsername("https://www.iwara.tv/video/abc123"))
+	assert.Equal(t, "", ParseUsername(""))
+}
+
+// TestParsePlaylistID tests the ParsePlaylistID function
+func TestParsePlaylistID(t *testing.T) {
+	assert.Equal(t, "list123", ParsePlaylistID("list123"))
+	assert.Equal(t, "list123", ParsePlaylistID("https://www.iwara.tv/playlist/list123/my-list"))
+	assert.Equal(t, "", ParsePlaylistID("https://www.iwara.tv/video/abc123"))
+}
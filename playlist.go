@@ -0,0 +1,107 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/utils"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// playlistDir 获取播放列表的下载目录,目录名为 "标题 [ID]",播放列表改名后继续使用原来的目录
+func playlistDir(playlist model.Playlist) string {
+	baseDir := consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.PLAYLIST_DIR
+	suffix := " [" + playlist.ID + "]"
+	if entries, err := os.ReadDir(baseDir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+				return baseDir + string(os.PathSeparator) + entry.Name()
+			}
+		}
+	}
+	return baseDir + string(os.PathSeparator) + files.SanitizeFileName(playlist.Title+suffix)
+}
+
+// playlist 同步下载播放列表中的视频
+func playlist(user *model.User, input string) error {
+	playlistID := utils.ParsePlaylistID(input)
+	if playlistID == "" {
+		return fmt.Errorf("无法解析播放列表: %s", input)
+	}
+	firstPage, err := request.GetPlaylistData(user, playlistID, 0)
+	if err != nil {
+		return fmt.Errorf("获取播放列表失败: %w", err)
+	}
+	log.Println("开始同步播放列表:", firstPage.Playlist.Title, "视频数量:", firstPage.Count)
+
+	filePath := playlistDir(firstPage.Playlist)
+	if err := files.CheckDirOrCreate(filePath); err != nil {
+		return err
+	}
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		return err
+	}
+
+	var fullCount int
+	listed := make(map[string]bool) // 当前播放列表中的视频
+	pool := newDownloadPool(user)
+	scanErr := rangeListPage(0, func(page int) (*model.PageDataRoot, error) {
+		if page == 0 {
+			return &firstPage.PageDataRoot, nil
+		}
+		pageData, err := request.GetPlaylistData(user, playlistID, page)
+		if err != nil {
+			return nil, err
+		}
+		return &pageData.PageDataRoot, nil
+	}, func(page int, pageData *model.PageDataRoot) (bool, error) {
+		for _, video := range pageData.Results {
+			listed[video.ID] = true
+			// 播放列表是手动整理的视频集合,不检查下载条件
+			if pool.Queue(filePath, db, video, nil) {
+				fullCount++
+			}
+		}
+		return false, nil
+	})
+
+	downloadCount := pool.Wait()
+	log.Println("播放列表一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+	if scanErr != nil {
+		return scanErr
+	}
+	// 只有完整获取了播放列表后才能判断视频是否被移除
+	syncPlaylistRemoved(user, filePath, listed)
+	return nil
+}
+
+// syncPlaylistRemoved 在数据库中标记已经从播放列表中移除的视频,重新加入播放列表的视频取消标记
+func syncPlaylistRemoved(user *model.User, filePath string, listed map[string]bool) {
+	if !user.MarkPlaylistRemoved {
+		return
+	}
+	err := updateVideoDatabase(filePath, func(db *model.Data) {
+		for id, data := range db.VideoMap {
+			if data.Video == nil || data.State == model.VideoStateEmbedded {
+				continue
+			}
+			if !listed[id] && data.State != model.VideoStateRemoved {
+				log.Println("视频已从播放列表中移除:", data.Video.Title)
+				data.State = model.VideoStateRemoved
+				db.VideoMap[id] = data
+			} else if listed[id] && data.State == model.VideoStateRemoved {
+				log.Println("视频重新加入了播放列表:", data.Video.Title)
+				data.State = ""
+				db.VideoMap[id] = data
+			}
+		}
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/date"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/progress"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// retentionFile 保留策略检查的视频文件
+type retentionFile struct {
+	filePath string    // 视频所在目录
+	id       string    // 视频ID
+	path     string    // 视频文件路径
+	size     int64     // 视频文件大小
+	monthEnd time.Time // 视频所在年月目录的最后一天
+	modTime  time.Time // 视频文件修改时间
+	keep     bool      // 是否永久保留
+}
+
+// keepForever 检查视频是否属于永久保留的作者或标签
+func keepForever(user *model.User, video *model.Result) bool {
+	if !user.Retention.KeepWhitelist || video == nil {
+		return false
+	}
+	for _, artist := range user.AllowedArtists() {
+		if video.User.Username == artist {
+			return true
+		}
+	}
+	for _, tag := range user.Tags {
+		for _, videoTag := range video.Tags {
+			if videoTag.ID == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// videoFilePaths 获取视频文件以及需要和视频一起清理的附属文件
+func videoFilePaths(videoPath string) []string {
+	return []string{videoPath, posterPath(videoPath), nfoPath(videoPath), infoJsonPath(videoPath), commentsPath(videoPath)}
+}
+
+// collectRetentionFiles 收集年/月目录中所有已下载的视频文件
+func collectRetentionFiles(user *model.User) []retentionFile {
+	var list []retentionFile
+	yearDirs, err := os.ReadDir(consts.FlagConf.WorkDIr)
+	if err != nil {
+		log.Println("读取下载目录失败:", err)
+		return nil
+	}
+	for _, yearDir := range yearDirs {
+		year, err := strconv.Atoi(yearDir.Name())
+		if err != nil || !yearDir.IsDir() {
+			continue
+		}
+		monthDirs, err := os.ReadDir(filepath.Join(consts.FlagConf.WorkDIr, yearDir.Name()))
+		if err != nil {
+			log.Println("读取下载目录失败:", err)
+			continue
+		}
+		for _, monthDir := range monthDirs {
+			month, err := strconv.Atoi(monthDir.Name())
+			if err != nil || !monthDir.IsDir() || month < 1 || month > 12 {
+				continue
+			}
+			filePath := filepath.Join(consts.FlagConf.WorkDIr, yearDir.Name(), monthDir.Name())
+			db, err := loadVideoDatabase(filePath)
+			if err != nil {
+				log.Println("读取数据库文件失败:", filePath, err)
+				continue
+			}
+			monthEnd := date.GetLastDayOfMonth(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local))
+			for id, data := range db.VideoMap {
+				if data.Video == nil || data.PrunedAt != nil {
+					continue
+				}
+				fileName, _ := findVideoFile(filePath, *data.Video)
+				if fileName == "" {
+					continue
+				}
+				videoPath := filepath.Join(filePath, fileName)
+				info, err := os.Stat(videoPath)
+				if err != nil {
+					continue
+				}
+				list = append(list, retentionFile{
+					filePath: filePath,
+					id:       id,
+					path:     videoPath,
+					size:     info.Size(),
+					monthEnd: monthEnd,
+					modTime:  info.ModTime(),
+					keep:     keepForever(user, data.Video),
+				})
+			}
+		}
+	}
+	return list
+}
+
+// pruneFile 删除视频文件或者移动到归档目录,并在数据库中标记为已清理
+func pruneFile(user *model.User, f retentionFile) error {
+	for _, path := range videoFilePaths(f.path) {
+		if !files.CheckFileExists(path) {
+			continue
+		}
+		if user.Retention.MoveTo == "" {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		// 保持年/月目录结构移动到归档目录
+		rel, err := filepath.Rel(consts.FlagConf.WorkDIr, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(user.Retention.MoveTo, rel)
+		if err := files.CheckDirOrCreate(filepath.Dir(target)); err != nil {
+			return err
+		}
+		if err := files.MoveFile(path, target); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	return updateVideoDatabase(f.filePath, func(db *model.Data) {
+		data, ok := db.VideoMap[f.id]
+		if !ok {
+			return
+		}
+		data.PrunedAt = &now
+		db.VideoMap[f.id] = data
+	})
+}
+
+// retention 依据保留策略清理年/月目录中的旧视频
+func retention(user *model.User) {
+	policy := user.Retention
+	if policy.MaxTotalSize <= 0 && policy.MaxAge <= 0 {
+		return
+	}
+	log.Println("开始执行保留策略")
+
+	list := collectRetentionFiles(user)
+	// 从最旧的视频开始清理
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].monthEnd.Equal(list[j].monthEnd) {
+			return list[i].monthEnd.Before(list[j].monthEnd)
+		}
+		return list[i].modTime.Before(list[j].modTime)
+	})
+
+	var total int64
+	for _, f := range list {
+		total += f.size
+	}
+	maxTotalSize := int64(policy.MaxTotalSize) * 1024 * 1024 * 1024
+	maxAge := time.Duration(policy.MaxAge) * time.Hour * 24
+
+	var pruneCount int
+	var pruneSize int64
+	for _, f := range list {
+		if f.keep {
+			continue
+		}
+		expired := policy.MaxAge > 0 && time.Since(f.monthEnd) > maxAge
+		oversize := policy.MaxTotalSize > 0 && total > maxTotalSize
+		if !expired && !oversize {
+			continue
+		}
+		if err := pruneFile(user, f); err != nil {
+			log.Println("清理视频失败:", f.path, err)
+			continue
+		}
+		log.Println("保留策略清理视频:", f.path, progress.FormatSize(f.size))
+		total -= f.size
+		pruneCount++
+		pruneSize += f.size
+	}
+	log.Println("保留策略执行完成, 一共清理", pruneCount, "个视频,释放", progress.FormatSize(pruneSize), "当前视频总大小", progress.FormatSize(total))
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/internal/request"
+	"IwaraDownload/model"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/utils"
+	"bufio"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// readVideoList 读取视频列表文件,每行一个视频地址或ID,文件名为 - 时从标准输入读取
+func readVideoList(listPath string) ([]string, error) {
+	var reader io.Reader = os.Stdin
+	if listPath != "-" {
+		file, err := os.Open(listPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var list []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// 跳过空行和注释
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, line)
+	}
+	return list, scanner.Err()
+}
+
+// single 下载指定的视频,不检查下载条件
+func single(user *model.User) {
+	inputs := consts.FlagConf.Videos
+	if consts.FlagConf.List != "" {
+		list, err := readVideoList(consts.FlagConf.List)
+		if err != nil {
+			log.Println("读取视频列表失败:", err)
+			return
+		}
+		inputs = append(inputs, list...)
+	}
+
+	filePath := consts.FlagConf.Output
+	if filePath == "" {
+		filePath = consts.FlagConf.WorkDIr + string(os.PathSeparator) + consts.SINGLE_DIR
+	}
+	if err := files.CheckDirOrCreate(filePath); err != nil {
+		log.Println("检查下载目录失败:", err)
+		return
+	}
+	log.Println("一共有", len(inputs), "个视频需要处理, 下载目录:", filePath)
+
+	var fullCount int
+	pool := newDownloadPool(user)
+	for _, input := range inputs {
+		id := utils.ParseVideoID(input)
+		if id == "" {
+			log.Println("无法解析视频地址, 跳过:", input)
+			continue
+		}
+		video, err := request.GetVideo(user, id)
+		if err != nil {
+			log.Println("获取视频详情失败:", id, err)
+			continue
+		}
+		log.Println("处理视频:", video.Title)
+		if video.EmbedUrl != "" {
+			saveEmbedVideo(user, filePath, *video)
+			continue
+		}
+		fullCount++
+
+		if f, _ := findVideoFile(filePath, *video); f != "" {
+			log.Printf("视频已存在: %s 跳过...\n", f)
+			saveVideoDatabase(filePath, *video, nil)
+			continue
+		}
+		pool.Add(filePath, *video)
+	}
+
+	downloadCount := pool.Wait()
+	log.Println("一共需要下载", fullCount, "个视频,本次下载", downloadCount)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/pkg/files"
+	"IwaraDownload/pkg/mp4"
+	"log"
+	"os"
+	"time"
+)
+
+// verifyDir 校验目录中数据库记录的所有视频文件,返回校验数量和损坏数量
+func verifyDir(filePath string) (int, int) {
+	db, err := loadVideoDatabase(filePath)
+	if err != nil {
+		log.Println("读取数据库文件失败:", filePath, err)
+		return 0, 0
+	}
+
+	var checkCount, corruptCount int
+	for id, data := range db.VideoMap {
+		if data.Video == nil {
+			continue
+		}
+		video := data.Video
+
+		fileName, definition := findVideoFile(filePath, *video)
+		if fileName == "" {
+			// 没有下载的视频不需要校验
+			continue
+		}
+		checkCount++
+
+		videoPath := filePath + string(os.PathSeparator) + fileName
+		expectSize := video.ExpectSize(definition)
+		if meta, err := mp4.ReadMetadata(videoPath); err == nil && meta.Title != "" {
+			// 写入过元数据的视频文件大小会发生变化,只校验文件结构
+			expectSize = 0
+		}
+		err := files.VerifyVideoFile(videoPath, expectSize)
+		if err == nil {
+			continue
+		}
+		corruptCount++
+		log.Printf("视频文件损坏: %s ID: %s 原因: %s\n", videoPath, id, err.Error())
+
+		if consts.FlagConf.Requeue {
+			if err := os.Remove(videoPath); err != nil {
+				log.Println("删除损坏的视频文件失败:", err)
+				continue
+			}
+			// 加入失败队列,之后的扫描任务中会重新下载
+			saveDownloadFailed(filePath, *video, err)
+		}
+	}
+	return checkCount, corruptCount
+}
+
+// verify 校验下载目录中所有已记录的视频文件
+func verify() {
+	start := time.Now()
+	var checkCount, corruptCount int
+	err := rangeVideoDatabase(func(filePath string) {
+		log.Println("正在校验目录:", filePath)
+		check, corrupt := verifyDir(filePath)
+		checkCount += check
+		corruptCount += corrupt
+	})
+	if err != nil {
+		log.Println("遍历下载目录失败:", err)
+	}
+	log.Println("校验任务完成, 一共校验", checkCount, "个视频,损坏", corruptCount, "个")
+	log.Println("本次校验任务耗时:", time.Since(start))
+}
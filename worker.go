@@ -0,0 +1,147 @@
+package main
+
+import (
+	"IwaraDownload/consts"
+	"IwaraDownload/model"
+	"errors"
+	"log"
+	"sync"
+)
+
+// downloadTask 下载任务
+type downloadTask struct {
+	filePath string       // 下载目录
+	video    model.Result // 视频数据
+	page     int          // 视频所在的列表页码,记录回溯进度时使用
+}
+
+// commentTask 等待获取评论的视频
+type commentTask struct {
+	videoPath string       // 视频文件路径
+	video     model.Result // 视频数据
+}
+
+// downloadPool 下载任务池
+// 页面扫描和视频下载分离,扫描到的视频放入有界队列中,由多个下载协程并发获取下载地址并下载
+type downloadPool struct {
+	user  *model.User
+	tasks chan downloadTask
+	wg    sync.WaitGroup
+	pages *pageTracker // 回溯进度记录,为空时不记录进度
+
+	lock          sync.Mutex
+	queued        map[string]bool // 已经加入过队列的视频,防止翻页时视频位置变动导致重复下载
+	downloadCount int             // 下载成功的数量
+	comments      []commentTask   // 下载完成后等待获取评论的视频,在所有下载完成后获取,避免占用下载协程
+}
+
+// newDownloadPool 创建下载任务池并启动下载协程
+func newDownloadPool(user *model.User) *downloadPool {
+	workers := user.DownloadWorkers
+	if workers < 1 {
+		workers = consts.DEFAULT_DOWNLOAD_WORKERS
+	}
+	p := &downloadPool{
+		user:   user,
+		tasks:  make(chan downloadTask, workers*consts.DOWNLOAD_QUEUE_FACTOR),
+		queued: make(map[string]bool),
+	}
+	log.Println("启动下载协程数量:", workers)
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Add 添加下载任务,队列已满时阻塞等待
+func (p *downloadPool) Add(filePath string, video model.Result) {
+	p.lock.Lock()
+	if p.queued[video.ID] {
+		p.lock.Unlock()
+		log.Println("视频已在下载队列中,跳过:", video.Title)
+		return
+	}
+	p.queued[video.ID] = true
+	p.lock.Unlock()
+
+	log.Println("视频加入下载队列:", video.Title)
+	task := downloadTask{filePath: filePath, video: video}
+	if p.pages != nil {
+		task.page = p.pages.add()
+	}
+	p.tasks <- task
+}
+
+// Queue 检查视频是否需要下载,需要时加入下载队列,返回视频是否符合下载条件
+// skip 为检查下载条件的函数,为 nil 时不检查下载条件,用于明确指定的视频
+func (p *downloadPool) Queue(filePath string, db model.Data, video model.Result, skip func(user *model.User, video model.Result) bool) bool {
+	log.Println("处理视频:", video.Title)
+	if skip != nil && skip(p.user, video) {
+		log.Println("视频不符合下载条件,跳过...")
+		return false
+	}
+	if video.EmbedUrl != "" {
+		saveEmbedVideo(p.user, filePath, video)
+		return false
+	}
+	if db.VideoMap[video.ID].PrunedAt != nil {
+		log.Println("视频已被保留策略清理,跳过...")
+		return false
+	}
+
+	if f, _ := findVideoFile(filePath, video); f != "" {
+		log.Printf("视频已存在: %s 跳过...\n", f)
+		saveVideoDatabase(filePath, video, nil)
+		return true
+	}
+	p.Add(filePath, video)
+	return true
+}
+
+// Wait 关闭队列并等待所有下载任务完成,然后获取下载完成的视频的评论,返回下载成功的数量
+func (p *downloadPool) Wait() int {
+	close(p.tasks)
+	p.wg.Wait()
+	for _, task := range p.comments {
+		saveComments(p.user, task.videoPath, task.video)
+	}
+	p.comments = nil
+	return p.downloadCount
+}
+
+// work 下载协程
+func (p *downloadPool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		p.download(task)
+		if p.pages != nil {
+			p.pages.done(task.page)
+		}
+	}
+}
+
+// download 下载单个视频并记录下载结果
+func (p *downloadPool) download(task downloadTask) {
+	videoPath, err := downloadVideo(p.user, task.filePath, task.video)
+	var embedErr *model.EmbedVideoError
+	if errors.As(err, &embedErr) {
+		// 列表数据中没有嵌入地址,获取视频详情后才发现是嵌入视频
+		task.video.EmbedUrl = embedErr.EmbedUrl
+		saveEmbedVideo(p.user, task.filePath, task.video)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		// 记录到失败队列,之后的扫描任务中重试
+		saveDownloadFailed(task.filePath, task.video, err)
+		return
+	}
+	removeDownloadFailed(task.filePath, task.video.ID)
+	p.lock.Lock()
+	p.downloadCount++
+	if p.user.ArchiveComments {
+		p.comments = append(p.comments, commentTask{videoPath: videoPath, video: task.video})
+	}
+	p.lock.Unlock()
+}